@@ -0,0 +1,194 @@
+package reco
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/ReconfigureIO/reco/printer"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BudgetFile is the default name of the file declaring a project's
+// resource budget, in the source directory.
+const BudgetFile = "reco-budget.yml"
+
+// Budget is a set of constraints on the resources used by a build.
+type Budget struct {
+	// Max is the maximum utilisation percentage of resources, keyed
+	// by resource name. See BuildResources.
+	Max map[string]float64
+	// MinFmax is the minimum clock frequency in MHz.
+	MinFmax float64
+}
+
+// budgetFile is the file representation of a budget e.g.
+//
+//	max:
+//	  lut: 80%
+//	  bram: 70%
+//	min_fmax: 250MHz
+type budgetFile struct {
+	Max     map[string]string `yaml:"max"`
+	MinFmax string            `yaml:"min_fmax"`
+}
+
+// LoadBudget loads a budget from file.
+func LoadBudget(file string) (Budget, error) {
+	var budget Budget
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return budget, err
+	}
+	var bf budgetFile
+	if err := yaml.Unmarshal(b, &bf); err != nil {
+		return budget, fmt.Errorf("invalid budget file %s: %v", file, err)
+	}
+	for resource, max := range bf.Max {
+		if err := budget.SetMax(resource, max); err != nil {
+			return budget, fmt.Errorf("invalid budget file %s: %v", file, err)
+		}
+	}
+	if bf.MinFmax != "" {
+		if err := budget.SetMinFmax(bf.MinFmax); err != nil {
+			return budget, fmt.Errorf("invalid budget file %s: %v", file, err)
+		}
+	}
+	return budget, nil
+}
+
+// Empty checks if the budget has no constraints.
+func (b Budget) Empty() bool {
+	return len(b.Max) == 0 && b.MinFmax == 0
+}
+
+// SetMax sets the maximum utilisation of resource as a percentage e.g. "80%".
+func (b *Budget) SetMax(resource, percent string) error {
+	name, ok := resourceName(resource)
+	if !ok {
+		return fmt.Errorf("unknown resource '%s'. Resources are %s", resource, strings.Join(BuildResources, ", "))
+	}
+	p, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(percent), "%"), 64)
+	if err != nil || p < 0 {
+		return fmt.Errorf("invalid percentage '%s' for %s", percent, resource)
+	}
+	if b.Max == nil {
+		b.Max = make(map[string]float64)
+	}
+	b.Max[name] = p
+	return nil
+}
+
+// SetMinFmax sets the minimum clock frequency e.g. "250MHz". Frequencies
+// without a unit are in MHz.
+func (b *Budget) SetMinFmax(frequency string) error {
+	f, err := parseFrequency(frequency)
+	if err != nil {
+		return err
+	}
+	b.MinFmax = f
+	return nil
+}
+
+// parseFrequency parses a frequency and returns it in MHz.
+func parseFrequency(frequency string) (float64, error) {
+	s := strings.ToLower(strings.TrimSpace(frequency))
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"ghz", 1000}, {"mhz", 1}, {"khz", 0.001}, {"hz", 0.000001},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid frequency '%s'", frequency)
+	}
+	return f * multiplier, nil
+}
+
+// BudgetViolation is a budget constraint not met by a build.
+type BudgetViolation struct {
+	Constraint string
+	Limit      string
+	Actual     string
+}
+
+// Check evaluates the budget against a build report and returns
+// the violated constraints. Constrained resources missing from the
+// report are violations, as they cannot be checked.
+func (b Budget) Check(report BuildReport) []BudgetViolation {
+	var violations []BudgetViolation
+	for _, name := range BuildResources {
+		max, ok := b.Max[name]
+		if !ok {
+			continue
+		}
+		usage, _ := report.Resource(name)
+		if usage.Used == 0 && usage.Available == 0 {
+			violations = append(violations, BudgetViolation{
+				Constraint: "max-" + name,
+				Limit:      fmt.Sprintf("%.2f%%", max),
+				Actual:     "not reported",
+			})
+			continue
+		}
+		if usage.Percent() > max {
+			violations = append(violations, BudgetViolation{
+				Constraint: "max-" + name,
+				Limit:      fmt.Sprintf("%.2f%%", max),
+				Actual:     fmt.Sprintf("%.2f%% (%d of %d)", usage.Percent(), usage.Used, usage.Available),
+			})
+		}
+	}
+	if b.MinFmax > 0 && report.ClockFrequency < b.MinFmax {
+		actual := "not reported"
+		if report.ClockFrequency > 0 {
+			actual = fmt.Sprintf("%.2f MHz", report.ClockFrequency)
+		}
+		violations = append(violations, BudgetViolation{
+			Constraint: "min-fmax",
+			Limit:      fmt.Sprintf("%.2f MHz", b.MinFmax),
+			Actual:     actual,
+		})
+	}
+	return violations
+}
+
+// ViolationsTable returns a table of budget violations.
+func ViolationsTable(violations []BudgetViolation) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "constraint", Title: "constraint"},
+			{Name: "limit", Title: "limit"},
+			{Name: "actual", Title: "actual"},
+		},
+	}
+	for _, v := range violations {
+		table.Body = append(table.Body, printer.Row{v.Constraint, v.Limit, v.Actual})
+	}
+	return table
+}
+
+// LoadBuildReport loads a build report saved to file, either as
+// returned by the platform or by 'reco build report --output json'.
+func LoadBuildReport(file string) (BuildReport, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return BuildReport{}, err
+	}
+	var report BuildReport
+	if err := json.Unmarshal(b, &report); err == nil {
+		return report, nil
+	}
+	// saved platform responses are escaped.
+	return parseBuildReport(string(b))
+}
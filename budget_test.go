@@ -0,0 +1,131 @@
+package reco
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFrequency(t *testing.T) {
+	frequencies := map[string]float64{
+		"250MHz":    250,
+		"250 mhz":   250,
+		"0.25GHz":   250,
+		"250000kHz": 250,
+		"250":       250,
+	}
+	for s, expected := range frequencies {
+		f, err := parseFrequency(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if f != expected {
+			t.Errorf("parseFrequency(%q) = %v, expected %v", s, f, expected)
+		}
+	}
+	if _, err := parseFrequency("fast"); err == nil {
+		t.Error("parseFrequency should fail for invalid frequencies")
+	}
+}
+
+func TestBudgetCheck(t *testing.T) {
+	report := BuildReport{
+		LUTs:           ResourceUsage{Used: 85, Available: 100},
+		BlockRAM:       ResourceUsage{Used: 50, Available: 100},
+		ClockFrequency: 240,
+	}
+
+	var budget Budget
+	if err := budget.SetMax("lut", "80%"); err != nil {
+		t.Fatal(err)
+	}
+	if err := budget.SetMax("BRAM", "70"); err != nil {
+		t.Fatal(err)
+	}
+	if err := budget.SetMinFmax("250MHz"); err != nil {
+		t.Fatal(err)
+	}
+	if err := budget.SetMax("flux-capacitor", "10%"); err == nil {
+		t.Error("SetMax should fail for unknown resources")
+	}
+
+	violations := budget.Check(report)
+	if len(violations) != 2 || violations[0].Constraint != "max-lut" || violations[1].Constraint != "min-fmax" {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+
+	report.LUTs.Used = 80
+	report.ClockFrequency = 300
+	if violations := budget.Check(report); len(violations) != 0 {
+		t.Errorf("unexpected violations %+v", violations)
+	}
+
+	// constrained resources missing from the report are violations.
+	if err := budget.SetMax("dsp", "50%"); err != nil {
+		t.Fatal(err)
+	}
+	violations = budget.Check(report)
+	if len(violations) != 1 || violations[0].Constraint != "max-dsp" || violations[0].Actual != "not reported" {
+		t.Errorf("expected an unreported dsp violation, got %+v", violations)
+	}
+}
+
+func TestLoadBudget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, BudgetFile)
+	content := "max:\n  lut: 80%\n  dsp: 50%\nmin_fmax: 0.2GHz\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	budget, err := LoadBudget(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget.Max["lut"] != 80 || budget.Max["dsp"] != 50 || budget.MinFmax != 200 {
+		t.Errorf("unexpected budget %+v", budget)
+	}
+}
+
+func TestLoadBuildReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	saved := BuildReport{ModuleName: `main "top" \ v2`, LUTs: ResourceUsage{Used: 10, Available: 100}}
+	b, err := json.Marshal(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"saved.json":    string(b),
+		"platform.json": testBuildReport,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report, err := LoadBuildReport(filepath.Join(dir, "saved.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ModuleName != saved.ModuleName || report.LUTs.Used != 10 {
+		t.Errorf("Expected %+v, found %+v", saved, report)
+	}
+	report, err = LoadBuildReport(filepath.Join(dir, "platform.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ModuleName != "main" || report.LUTs.Used != 59140 {
+		t.Errorf("unexpected platform report %+v", report)
+	}
+}
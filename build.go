@@ -1,24 +1,22 @@
 package reco
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
-	"strings"
-	"time"
 
 	"github.com/ReconfigureIO/reco/logger"
 	"github.com/ReconfigureIO/reco/printer"
-	humanize "github.com/dustin/go-humanize"
 )
 
-var _ Job = &buildJob{}
+var _ BuildJob = &buildJob{}
 
 // BuildReporter can return build reports.
 type BuildReporter interface {
-	// Report returns a formatted JSON build report.
-	Report(id string) (string, error)
+	// Report returns the report of a completed build.
+	Report(id string) (BuildReport, error)
+	// Trend returns metric from the reports of the last completed builds.
+	Trend(metric string, last int) (BuildTrend, error)
 }
 
 type buildJob struct {
@@ -46,24 +44,42 @@ func (b buildJob) prepareBuild(message string) (string, error) {
 	return respJSON.Value.ID, err
 }
 
+// Start starts a build with positional args.
+//
+// Deprecated: use StartBuild.
 func (b buildJob) Start(args Args) (string, error) {
-	srcDir := String(args.At(0))
-	wait := Bool(args.At(1))
-	message := String(args.At(2))
+	return b.StartBuild(buildOptions(args))
+}
 
-	logger.Info.Println("preparing build")
-	id, err := b.prepareBuild(message)
+func (b buildJob) StartBuild(opts BuildOptions) (string, error) {
+	message := opts.Message
+
+	// archive first, so that missing dependencies leave no build
+	// without a source.
+	logger.Info.Println("archiving")
+	srcArchive, err := archiveDir(opts.SourceDir, opts.Vendor)
 	if err != nil {
 		return "", err
 	}
-	logger.Info.Println("done. Build ID: ", id)
+	logger.Info.Println("done")
 
-	logger.Info.Println("archiving")
-	srcArchive, err := archiveDir(srcDir)
+	// record git metadata, so the build can be referred to by branch.
+	record, isGit := gitRecord(opts.SourceDir)
+	if message == "" && isGit {
+		message = record.String()
+	}
+
+	logger.Info.Println("preparing build")
+	id, err := b.prepareBuild(message)
 	if err != nil {
 		return "", err
 	}
-	logger.Info.Println("done")
+	logger.Info.Println("done. Build ID: ", id)
+	if isGit {
+		if err := recordBuild(id, record); err != nil {
+			logger.Info.Println("could not record build git metadata: ", err)
+		}
+	}
 
 	logger.Info.Println("uploading")
 	if err := b.uploadJob("build", id, srcArchive); err != nil {
@@ -72,14 +88,21 @@ func (b buildJob) Start(args Args) (string, error) {
 	logger.Info.Println("done")
 	logger.Info.Println()
 
-	if wait {
+	switch opts.Wait {
+	case WaitNone:
+	case WaitStatus:
+		logger.Info.Println("waiting for build to finish")
+		if _, err := b.waitForJob(JobTypeBuild, id, jobInfo.IsCompleted); err != nil {
+			return id, err
+		}
+	default:
 		b.waitAndLog("build", id)
 	}
 
 	return id, nil
 }
 
-func (b buildJob) Status(id string) string {
+func (b buildJob) Status(id string) Status {
 	return b.clientImpl.getStatus("build", id)
 }
 
@@ -88,68 +111,46 @@ func (b buildJob) Stop(id string) error {
 }
 
 func (b buildJob) List(filter M) (printer.Table, error) {
-	var table printer.Table
 	allProjects := filter.Bool("all")
 	builds, err := b.clientImpl.listBuilds(filter)
 	if err != nil {
-		return table, err
-	}
-	var body [][]string
-	for _, build := range builds {
-		buildTime := "-"
-		if !build.Time.IsZero() {
-			buildTime = humanize.Time(build.Time)
-		}
-
-		row := []string{
-			build.ID,
-			build.Status,
-			buildTime,
-			timeRounder(build.Duration).Nearest(time.Second),
-			build.Message,
-		}
-		if allProjects {
-			row = append(row, build.Project)
-		}
-
-		body = append(body, row)
-	}
-
-	table = printer.Table{
-		Header: []string{"build id", "status", "started", "duration", "message"},
-		Body:   body,
-	}
-	if allProjects {
-		table.Header = append(table.Header, "project")
-	}
-
-	return table, nil
+		return printer.Table{}, err
+	}
+	return jobTable(builds, allProjects,
+		colID.titled("build id"),
+		colStatus,
+		colStarted,
+		colDuration,
+		colMessage,
+	), nil
 }
 
 func (b buildJob) Log(id string, writer io.Writer) error {
 	return b.clientImpl.logJob("build", id)
 }
 
-type buildReport struct {
-	Report string `json:"report"`
-}
+func (b buildJob) Report(id string) (BuildReport, error) {
+	if report, ok := cachedReport(id); ok {
+		return report, nil
+	}
 
-func (b buildJob) Report(id string) (string, error) {
-	var req = b.apiRequest(endpoints.builds.Report())
-	req.param("id", id)
+	req, err := b.jobRequest(JobTypeBuild, opReport, id)
+	if err != nil {
+		return BuildReport{}, err
+	}
 	resp, err := req.Do("GET", nil)
 	if err != nil {
-		return "", err
+		return BuildReport{}, err
 	}
 	switch resp.StatusCode {
 	case 404:
-		return "", errors.New("Report not found")
+		return BuildReport{}, errors.New("Report not found")
 	case 204:
-		return "", errors.New("No report generated. Reports are only generated for COMPLETED builds")
+		return BuildReport{}, errors.New("No report generated. Reports are only generated for COMPLETED builds")
 	case 200:
 		break
 	default:
-		return "", errors.New("Unknown error occured")
+		return BuildReport{}, errors.New("Unknown error occured")
 	}
 
 	var apiResp struct {
@@ -159,15 +160,15 @@ func (b buildJob) Report(id string) (string, error) {
 	}
 	err = json.NewDecoder(resp.Body).Decode(&apiResp)
 	if err != nil {
-		return "", err
+		return BuildReport{}, err
 	}
 
-	validJSONReport := strings.Replace(apiResp.Value.Report, "\\", "", -1)
-	var prettyJSONReport bytes.Buffer
-	err = json.Indent(&prettyJSONReport, []byte(validJSONReport), "", "\t")
+	report, err := parseBuildReport(apiResp.Value.Report)
 	if err != nil {
-		return "", err
+		return report, err
 	}
-
-	return prettyJSONReport.String(), nil
+	if err := cacheReport(id, report); err != nil {
+		logger.Info.Println("could not cache report: ", err)
+	}
+	return report, nil
 }
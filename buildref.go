@@ -0,0 +1,196 @@
+package reco
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/spf13/viper"
+)
+
+// buildRecordsFile records the git metadata of builds started from
+// the source directory, in the local config directory.
+const buildRecordsFile = "builds.json"
+
+// buildRef is a symbolic reference to a completed build:
+//
+//	latest            the newest completed build
+//	latest@<branch>   the newest completed build of a git branch
+//	<ref>~N           the Nth completed build before ref, where ref
+//	                  is latest, latest@<branch>, build or a build ID
+//
+// build is an alias of latest e.g. build~1 is the completed build
+// before the latest.
+type buildRef struct {
+	// ID is the build ID the ref is relative to, or empty for latest.
+	ID     string
+	Branch string
+	Back   int
+}
+
+// parseBuildRef parses a build reference. It returns false if ref
+// is a plain build ID.
+func parseBuildRef(ref string) (buildRef, bool, error) {
+	var r buildRef
+	base := ref
+	if i := strings.LastIndex(ref, "~"); i >= 0 {
+		n, err := strconv.Atoi(ref[i+1:])
+		if err != nil || n < 0 {
+			return r, false, fmt.Errorf("invalid build reference '%s'. Expected <ref>~N with N a number", ref)
+		}
+		r.Back = n
+		base = ref[:i]
+	}
+	switch {
+	case base == "latest" || base == "build":
+	case strings.HasPrefix(base, "latest@"):
+		r.Branch = strings.TrimPrefix(base, "latest@")
+		if r.Branch == "" {
+			return r, false, fmt.Errorf("invalid build reference '%s'. Expected latest@<branch>", ref)
+		}
+	case base != ref:
+		r.ID = base
+	default:
+		return r, false, nil
+	}
+	return r, true, nil
+}
+
+// buildRecord is the git metadata of a build.
+type buildRecord struct {
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit,omitempty"`
+}
+
+func buildRecordsFileName() string {
+	return filepath.Join(viper.GetString(ConfigDirKey), buildRecordsFile)
+}
+
+func loadBuildRecords() map[string]buildRecord {
+	records := make(map[string]buildRecord)
+	if b, err := ioutil.ReadFile(buildRecordsFileName()); err == nil {
+		json.Unmarshal(b, &records)
+	}
+	return records
+}
+
+// recordBuild records the git metadata of build id.
+func recordBuild(id string, record buildRecord) error {
+	records := loadBuildRecords()
+	records[id] = record
+	b, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(buildRecordsFileName(), b, 0644)
+}
+
+// gitRecord returns the git branch and commit of dir, if it is
+// in a git repository.
+func gitRecord(dir string) (buildRecord, bool) {
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	record := buildRecord{
+		Branch: git("rev-parse", "--abbrev-ref", "HEAD"),
+		Commit: git("rev-parse", "--short", "HEAD"),
+	}
+	if record.Branch == "HEAD" {
+		// detached head.
+		record.Branch = ""
+	}
+	return record, record.Commit != ""
+}
+
+// String returns the record as branch@commit.
+func (r buildRecord) String() string {
+	if r.Branch == "" {
+		return r.Commit
+	}
+	return r.Branch + "@" + r.Commit
+}
+
+// onBranch checks if build is of branch, by its recorded git metadata
+// or a word of its message.
+func onBranch(build jobInfo, records map[string]buildRecord, branch string) bool {
+	if record, ok := records[build.ID]; ok && record.Branch != "" {
+		return record.Branch == branch
+	}
+	words := strings.FieldsFunc(build.Message, func(r rune) bool {
+		return strings.ContainsRune(" \t\n@:,;[]()", r)
+	})
+	for _, word := range words {
+		if word == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// selectBuild returns the ID of the build ref refers to, from completed
+// builds listed newest first.
+func selectBuild(builds []jobInfo, ref buildRef, records map[string]buildRecord) (string, error) {
+	if ref.Branch != "" {
+		var onBranchBuilds []jobInfo
+		for _, build := range builds {
+			if onBranch(build, records, ref.Branch) {
+				onBranchBuilds = append(onBranchBuilds, build)
+			}
+		}
+		builds = onBranchBuilds
+	}
+	start := 0
+	if ref.ID != "" {
+		start = -1
+		for i, build := range builds {
+			if build.ID == ref.ID {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return "", fmt.Errorf("build %s is not a completed build of the project", ref.ID)
+		}
+	}
+	i := start + ref.Back
+	if i >= len(builds) {
+		switch {
+		case len(builds) == 0 && ref.Branch != "":
+			return "", fmt.Errorf("no completed builds of branch %s found", ref.Branch)
+		case len(builds) == 0:
+			return "", fmt.Errorf("no completed builds found")
+		}
+		return "", fmt.Errorf("only %d completed builds found", len(builds)-start)
+	}
+	return builds[i].ID, nil
+}
+
+// resolveBuild returns the build ID a build reference refers to.
+// Build IDs resolve to themselves.
+func (p *clientImpl) resolveBuild(ref string) (string, error) {
+	r, ok, err := parseBuildRef(ref)
+	if err != nil || !ok {
+		return ref, err
+	}
+	builds, err := p.listBuilds(M{"status": StatusCompleted})
+	if err != nil {
+		return "", err
+	}
+	id, err := selectBuild(builds, r, loadBuildRecords())
+	if err != nil {
+		return "", err
+	}
+	logger.Info.Printf("%s is build %s", ref, id)
+	return id, nil
+}
@@ -0,0 +1,76 @@
+package reco
+
+import "testing"
+
+func TestParseBuildRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		symbolic bool
+		expected buildRef
+	}{
+		{"4d6e1a2b-0000", false, buildRef{}},
+		{"latest", true, buildRef{}},
+		{"build~2", true, buildRef{Back: 2}},
+		{"latest@feature/x~1", true, buildRef{Branch: "feature/x", Back: 1}},
+		{"4d6e1a2b~3", true, buildRef{ID: "4d6e1a2b", Back: 3}},
+	}
+	for _, test := range tests {
+		ref, symbolic, err := parseBuildRef(test.ref)
+		if err != nil {
+			t.Errorf("%s: %v", test.ref, err)
+			continue
+		}
+		if symbolic != test.symbolic || ref != test.expected {
+			t.Errorf("%s: expected %+v %v, got %+v %v", test.ref, test.expected, test.symbolic, ref, symbolic)
+		}
+	}
+	for _, invalid := range []string{"latest~x", "latest~-1", "latest@"} {
+		if _, _, err := parseBuildRef(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSelectBuild(t *testing.T) {
+	// newest first.
+	builds := []jobInfo{
+		{ID: "b5", Message: "fix timing"},
+		{ID: "b4", Message: "main@1a2b3c4"},
+		{ID: "b3", Message: "[feature-x] wider bus"},
+		{ID: "b2"},
+		{ID: "b1", Message: "main@0f0f0f0"},
+	}
+	records := map[string]buildRecord{"b5": {Branch: "feature-x", Commit: "ccccccc"}}
+
+	tests := []struct {
+		ref string
+		id  string
+	}{
+		{"latest", "b5"},
+		{"build~1", "b4"},
+		{"latest~4", "b1"},
+		{"latest@main", "b4"},
+		{"latest@main~1", "b1"},
+		{"latest@feature-x", "b5"},
+		{"latest@feature-x~1", "b3"},
+		{"b4~2", "b2"},
+	}
+	for _, test := range tests {
+		ref, _, err := parseBuildRef(test.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := selectBuild(builds, ref, records)
+		if err != nil {
+			t.Errorf("%s: %v", test.ref, err)
+		} else if id != test.id {
+			t.Errorf("%s: expected %s, got %s", test.ref, test.id, id)
+		}
+	}
+	for _, invalid := range []string{"latest~5", "latest@main~2", "latest@release", "b9~1"} {
+		ref, _, _ := parseBuildRef(invalid)
+		if _, err := selectBuild(builds, ref, records); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package reco
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// BuildReport is the area and timing report of a completed build.
+type BuildReport struct {
+	ModuleName      string        `json:"moduleName,omitempty"`
+	PartName        string        `json:"partName,omitempty"`
+	LUTs            ResourceUsage `json:"lutSummary"`
+	Registers       ResourceUsage `json:"regSummary"`
+	BlockRAM        ResourceUsage `json:"blockRamSummary"`
+	UltraRAM        ResourceUsage `json:"ultraRamSummary"`
+	DSPBlocks       ResourceUsage `json:"dspBlockSummary"`
+	WeightedAverage ResourceUsage `json:"weightedAverage"`
+	// ClockFrequency is the achieved clock frequency in MHz.
+	ClockFrequency float64 `json:"clockFrequency,omitempty"`
+	// TimingSlack is the worst timing slack in nanoseconds.
+	TimingSlack float64 `json:"timingSlack,omitempty"`
+}
+
+// ResourceUsage is the utilisation of an FPGA resource.
+type ResourceUsage struct {
+	Description string  `json:"description,omitempty"`
+	Used        int     `json:"used"`
+	Available   int     `json:"available"`
+	Utilisation float64 `json:"utilisation"`
+}
+
+// Percent returns the utilisation as a percentage of available resources.
+func (u ResourceUsage) Percent() float64 {
+	if u.Available > 0 {
+		return 100 * float64(u.Used) / float64(u.Available)
+	}
+	return u.Utilisation
+}
+
+// BuildResources are the names of the resources in a build report.
+var BuildResources = []string{"lut", "register", "bram", "uram", "dsp"}
+
+var buildResourceDescriptions = map[string]string{
+	"lut":      "CLB LUTs",
+	"register": "CLB Registers",
+	"bram":     "Block RAM Tiles",
+	"uram":     "UltraRAM Blocks",
+	"dsp":      "DSP Blocks",
+}
+
+// resourceName returns the resource name of name or its alias.
+func resourceName(name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case "lut", "luts":
+		return "lut", true
+	case "register", "registers", "reg":
+		return "register", true
+	case "bram":
+		return "bram", true
+	case "uram":
+		return "uram", true
+	case "dsp":
+		return "dsp", true
+	}
+	return "", false
+}
+
+// Resource returns the usage of a resource by name. See BuildResources.
+func (r BuildReport) Resource(name string) (ResourceUsage, bool) {
+	name, _ = resourceName(name)
+	switch name {
+	case "lut":
+		return r.LUTs, true
+	case "register":
+		return r.Registers, true
+	case "bram":
+		return r.BlockRAM, true
+	case "uram":
+		return r.UltraRAM, true
+	case "dsp":
+		return r.DSPBlocks, true
+	}
+	return ResourceUsage{}, false
+}
+
+func (r BuildReport) description(name string) string {
+	if u, _ := r.Resource(name); u.Description != "" {
+		return u.Description
+	}
+	return buildResourceDescriptions[name]
+}
+
+// Table returns the resource utilisation table of the report.
+func (r BuildReport) Table() printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "resource", Title: "resource"},
+			{Name: "description", Title: "description"},
+			{Name: "used", Title: "used"},
+			{Name: "available", Title: "available"},
+			{Name: "utilisation", Title: "utilisation"},
+		},
+	}
+	for _, name := range BuildResources {
+		u, _ := r.Resource(name)
+		table.Body = append(table.Body, printer.Row{
+			name,
+			r.description(name),
+			u.Used,
+			u.Available,
+			printer.Percent(u.Percent()),
+		})
+	}
+	return table
+}
+
+// DiffTable returns the per resource changes from r to other.
+func (r BuildReport) DiffTable(other BuildReport) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "resource", Title: "resource"},
+			{Name: "before", Title: "before"},
+			{Name: "after", Title: "after"},
+			{Name: "change", Title: "change"},
+			{Name: "utilisation_before", Title: "utilisation before"},
+			{Name: "utilisation_after", Title: "utilisation after"},
+		},
+	}
+	for _, name := range BuildResources {
+		before, _ := r.Resource(name)
+		after, _ := other.Resource(name)
+		table.Body = append(table.Body, printer.Row{
+			name,
+			before.Used,
+			after.Used,
+			printer.Change(after.Used - before.Used),
+			printer.Percent(before.Percent()),
+			printer.Percent(after.Percent()),
+		})
+	}
+	return table
+}
+
+// parseBuildReport parses a build report as returned by the platform.
+func parseBuildReport(report string) (BuildReport, error) {
+	var r BuildReport
+	// the platform escapes the report JSON.
+	validJSONReport := strings.Replace(report, "\\", "", -1)
+	err := json.Unmarshal([]byte(validJSONReport), &r)
+	return r, err
+}
@@ -0,0 +1,52 @@
+package reco
+
+import (
+	"testing"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+const testBuildReport = `{\"moduleName\":\"main\",\"partName\":\"xcvu9p-flgb2104-2-i\",` +
+	`\"lutSummary\":{\"description\":\"CLB LUTs\",\"used\":59140,\"available\":1182240,\"utilisation\":5},` +
+	`\"regSummary\":{\"description\":\"CLB Registers\",\"used\":80000,\"available\":2364480,\"utilisation\":3.38},` +
+	`\"blockRamSummary\":{\"description\":\"Block RAM Tile\",\"used\":216,\"available\":2160,\"utilisation\":10},` +
+	`\"dspBlockSummary\":{\"description\":\"DSPs\",\"used\":0,\"available\":6840,\"utilisation\":0},` +
+	`\"clockFrequency\":250}`
+
+func TestParseBuildReport(t *testing.T) {
+	report, err := parseBuildReport(testBuildReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PartName != "xcvu9p-flgb2104-2-i" {
+		t.Errorf("unexpected part name %q", report.PartName)
+	}
+	if report.LUTs.Used != 59140 || report.BlockRAM.Percent() != 10 {
+		t.Errorf("unexpected utilisation %+v %+v", report.LUTs, report.BlockRAM)
+	}
+	if report.ClockFrequency != 250 {
+		t.Errorf("unexpected clock frequency %v", report.ClockFrequency)
+	}
+	if _, ok := report.Resource("unknown"); ok {
+		t.Error("Resource returned an unknown resource")
+	}
+}
+
+func TestBuildReportDiff(t *testing.T) {
+	before, err := parseBuildReport(testBuildReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := before
+	after.LUTs.Used += 100
+	after.BlockRAM.Used -= 16
+
+	diff := before.DiffTable(after)
+	changes := map[string]printer.Change{}
+	for i := range diff.Body {
+		changes[diff.Value(i, "resource").(string)] = diff.Value(i, "change").(printer.Change)
+	}
+	if changes["lut"] != 100 || changes["bram"] != -16 || changes["dsp"] != 0 {
+		t.Errorf("unexpected changes %v", changes)
+	}
+}
@@ -0,0 +1,149 @@
+package reco
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+// reportCacheDir is the directory reports of completed builds are
+// cached in, in the global config directory. Reports of completed
+// builds never change.
+const reportCacheDir = "reports"
+
+// BuildTrend is a build report metric over a series of builds,
+// oldest first.
+type BuildTrend struct {
+	Metric string
+	Points []TrendPoint
+}
+
+// TrendPoint is the value of a metric for a build.
+type TrendPoint struct {
+	BuildID string
+	Started time.Time
+	Message string
+	Value   float64
+}
+
+// TrendMetrics are the metrics trends can be shown for. Resource
+// metrics are utilisation percentages, fmax is the clock frequency
+// in MHz.
+var TrendMetrics = append(append([]string{}, BuildResources...), "fmax")
+
+// trendMetric returns the metric name of name and a function
+// to extract it from a report.
+func trendMetric(name string) (string, func(BuildReport) float64, error) {
+	if strings.ToLower(name) == "fmax" {
+		return "fmax", func(r BuildReport) float64 { return r.ClockFrequency }, nil
+	}
+	resource, ok := resourceName(name)
+	if !ok {
+		return "", nil, fmt.Errorf("unknown metric '%s'. Metrics are %s", name, strings.Join(TrendMetrics, ", "))
+	}
+	return resource, func(r BuildReport) float64 {
+		usage, _ := r.Resource(resource)
+		return usage.Percent()
+	}, nil
+}
+
+// Values returns the values of the trend, oldest first.
+func (t BuildTrend) Values() []float64 {
+	values := make([]float64, len(t.Points))
+	for i, p := range t.Points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// Table returns the trend as a table, oldest first.
+func (t BuildTrend) Table() printer.Table {
+	title := t.Metric + " utilisation"
+	if t.Metric == "fmax" {
+		title = "fmax (MHz)"
+	}
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "build_id", Title: "build id"},
+			{Name: "started", Title: "started"},
+			{Name: t.Metric, Title: title},
+			{Name: "message", Title: "message"},
+		},
+	}
+	for _, p := range t.Points {
+		var value interface{} = printer.Percent(p.Value)
+		if t.Metric == "fmax" {
+			value = p.Value
+		}
+		table.Body = append(table.Body, printer.Row{p.BuildID, p.Started, value, p.Message})
+	}
+	return table
+}
+
+// Trend returns metric from the reports of the last completed builds
+// of the project. Builds without a report are skipped.
+func (b buildJob) Trend(metric string, last int) (BuildTrend, error) {
+	name, value, err := trendMetric(metric)
+	if err != nil {
+		return BuildTrend{}, err
+	}
+	builds, err := b.listBuilds(M{"status": StatusCompleted, "limit": last})
+	if err != nil {
+		return BuildTrend{}, err
+	}
+	trend := BuildTrend{Metric: name}
+	// builds are listed newest first.
+	for i := len(builds) - 1; i >= 0; i-- {
+		build := builds[i]
+		report, err := b.Report(build.ID)
+		if err != nil {
+			logger.Info.Printf("skipping build %s: %v", build.ID, err)
+			continue
+		}
+		trend.Points = append(trend.Points, TrendPoint{
+			BuildID: build.ID,
+			Started: build.Time,
+			Message: build.Message,
+			Value:   value(report),
+		})
+	}
+	return trend, nil
+}
+
+func reportCacheFile(id string) string {
+	return filepath.Join(viper.GetString(GlobalConfigDirKey), reportCacheDir, id+".json")
+}
+
+// cachedReport returns the cached report of build id, if any.
+func cachedReport(id string) (BuildReport, bool) {
+	var report BuildReport
+	b, err := ioutil.ReadFile(reportCacheFile(id))
+	if err != nil {
+		return report, false
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		return report, false
+	}
+	return report, true
+}
+
+// cacheReport caches the report of build id.
+func cacheReport(id string, report BuildReport) error {
+	file := reportCacheFile(id)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0644)
+}
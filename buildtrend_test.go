@@ -0,0 +1,57 @@
+package reco
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestTrendMetric(t *testing.T) {
+	report, err := parseBuildReport(testBuildReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for metric, expected := range map[string]float64{"DSP": 0, "bram": 10, "fmax": 250} {
+		_, value, err := trendMetric(metric)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if v := value(report); v != expected {
+			t.Errorf("%s = %v, expected %v", metric, v, expected)
+		}
+	}
+	if _, _, err := trendMetric("flops"); err == nil {
+		t.Error("expected error for unknown metric")
+	}
+}
+
+func TestReportCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	viper.Set(GlobalConfigDirKey, dir)
+	defer viper.Set(GlobalConfigDirKey, nil)
+
+	if _, ok := cachedReport("build1"); ok {
+		t.Error("unexpected cached report")
+	}
+	report, err := parseBuildReport(testBuildReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheReport("build1", report); err != nil {
+		t.Fatal(err)
+	}
+	cached, ok := cachedReport("build1")
+	if !ok {
+		t.Fatal("report not cached")
+	}
+	if cached.LUTs != report.LUTs || cached.ClockFrequency != report.ClockFrequency {
+		t.Errorf("cached report %+v does not match %+v", cached, report)
+	}
+}
@@ -11,11 +11,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
 	"github.com/spf13/viper"
 )
 
@@ -41,7 +41,7 @@ const (
 
 var (
 	alternativePlatformServer string
-	errUnsupported            = errors.New("That command is not supported by the reconfigure.io platform")
+	errUnsupported            = errors.New("That command is not supported by the provider")
 	errMissingServer          = errors.New("PLATFORM_SERVER config or environment variable not set")
 	errAuthRequired           = errors.New("Authentication required. Run 'reco auth' to authenticate")
 	errAuthFailed             = errors.New("Authentication failed. Run 'reco auth' to try again")
@@ -65,11 +65,11 @@ type Client interface {
 	// Auth authenticates the user.
 	Auth(token string) error
 	// Test handles simulation actions.
-	Test() Job
+	Test() SimulationJob
 	// Build handles build actions.
-	Build() Job
+	Build() BuildJob
 	// Deployment handles deployment actions.
-	Deployment() Job
+	Deployment() DeploymentJob
 	// Project handles project actions.
 	Project() ProjectConfig
 	// Graph handles graph actions.
@@ -100,13 +100,13 @@ func NewClient() Client {
 	return &clientImpl{}
 }
 
-func (p *clientImpl) Build() Job {
+func (p *clientImpl) Build() BuildJob {
 	return &buildJob{p}
 }
-func (p *clientImpl) Test() Job {
+func (p *clientImpl) Test() SimulationJob {
 	return &testJob{p}
 }
-func (p *clientImpl) Deployment() Job {
+func (p *clientImpl) Deployment() DeploymentJob {
 	return &deploymentJob{p}
 }
 func (p *clientImpl) Project() ProjectConfig {
@@ -264,7 +264,7 @@ func (p *clientImpl) logJob(eventType string, id string) error {
 	return p.logs(eventType, id)
 }
 
-func (p *clientImpl) getStatus(jobType string, id string) string {
+func (p *clientImpl) getStatus(jobType string, id string) Status {
 	job, err := p.getJob(jobType, id)
 	if err == nil && job.Status != "" {
 		return job.Status
@@ -272,44 +272,34 @@ func (p *clientImpl) getStatus(jobType string, id string) string {
 	return StatusErrored
 }
 
-func (p *clientImpl) waitForStatus(jobType string, id string, targetStatus string) error {
-	status := StatusSubmitted
-	prevStatus := ""
-	for status != targetStatus {
-		status = strings.ToUpper(p.getStatus(jobType, id))
+// waitForStatus waits for a job to be in targetStatus. It returns
+// errUnexpectedTermination if the job can no longer reach it.
+func (p *clientImpl) waitForStatus(jobType string, id string, targetStatus Status) error {
+	kind, err := lookupJobKind(jobType)
+	if err != nil {
+		return err
+	}
+	var prevStatus Status
+	for {
+		status := p.getStatus(jobType, id)
 		if status != prevStatus {
-			logger.Info.Println("status: ", status)
+			logger.Info.Println("status: ", printer.Colorize(string(status), statusColor(status)))
+			if prevStatus != "" && !prevStatus.CanReach(status) {
+				logger.Error.Printf("warning: unexpected status change from %s to %s", prevStatus, status)
+			}
 			prevStatus = status
-			switch jobType {
-			case "deployment":
-				switch status {
-				case StatusQueued:
-					logger.Info.Println("Waiting for FPGA instance to be available")
-				case StatusSubmitted:
-					logger.Info.Println("Waiting for request to be queued")
-				default:
-				}
-			case "build":
-				switch status {
-				case StatusQueued:
-					logger.Info.Println("Waiting for job to start")
-				default:
-				}
-			case "simulation":
-				switch status {
-				case StatusQueued:
-					logger.Info.Println("Waiting for job to start")
-				default:
-				}
-			default:
+			if message, ok := kind.waiting[status]; ok {
+				logger.Info.Println(message)
 			}
 		}
-		if isCompleted(status) {
+		if status == targetStatus {
+			return nil
+		}
+		if !status.CanReach(targetStatus) {
 			return errUnexpectedTermination
 		}
 		time.Sleep(10 * time.Second)
 	}
-	return nil
 }
 
 func (p *clientImpl) waitAndLog(jobType string, id string) error {
@@ -329,17 +319,10 @@ func (p *clientImpl) getJob(jobType string, id string) (jobInfo, error) {
 	var apiResp struct {
 		Job jobInfo `json:"value"`
 	}
-	var endpoint string
-	switch jobType {
-	case JobTypeSimulation:
-		endpoint = endpoints.simulations.Item()
-	case JobTypeDeployment:
-		endpoint = endpoints.deployments.Item()
-	default:
-		endpoint = endpoints.builds.Item()
-	}
-	req := p.apiRequest(endpoint)
-	req.param("id", id)
+	req, err := p.jobRequest(jobType, opGet, id)
+	if err != nil {
+		return apiResp.Job, err
+	}
 	resp, err := req.Do("GET", nil)
 	if err != nil {
 		return apiResp.Job, err
@@ -348,22 +331,43 @@ func (p *clientImpl) getJob(jobType string, id string) (jobInfo, error) {
 	return apiResp.Job, err
 }
 
+// waitForJob polls a job until done returns true for it.
+func (p *clientImpl) waitForJob(jobType string, id string, done func(jobInfo) bool) (jobInfo, error) {
+	for {
+		job, err := p.getJob(jobType, id)
+		if err != nil {
+			return job, err
+		}
+		if done(job) {
+			return job, nil
+		}
+		time.Sleep(waitInterval)
+	}
+}
+
+// streamLog streams the logs of a job to w.
+func (p *clientImpl) streamLog(jobType, id string, w io.Writer) error {
+	req, err := p.jobRequest(jobType, opLog, id)
+	if err != nil {
+		return err
+	}
+	resp, err := req.Do("GET", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
 // waitForLog attempts to stream logs. If peek is true, it ensures log streaming has started
 // and returns the body for the caller to read remaining contents.
 // Otherwise, logs are streamed to stderr.
 func (p *clientImpl) waitForLog(jobType, id string, peek bool) (io.ReadCloser, error) {
-	var endpoint string
-	switch jobType {
-	case JobTypeSimulation:
-		endpoint = endpoints.simulations.Log()
-	case JobTypeDeployment:
-		endpoint = endpoints.deployments.Log()
-	default:
-		endpoint = endpoints.builds.Log()
-	}
-	req := p.apiRequest(endpoint)
-	req.param("id", id)
-
+	req, err := p.jobRequest(jobType, opLog, id)
+	if err != nil {
+		return nil, err
+	}
 	resp, err := req.Do("GET", nil)
 	if err != nil {
 		return nil, err
@@ -393,25 +397,22 @@ func (p *clientImpl) waitForLog(jobType, id string, peek bool) (io.ReadCloser, e
 }
 
 func (p *clientImpl) uploadJob(jobType string, id string, srcArchive string) error {
-	var endpoint string
-	switch jobType {
-	case JobTypeSimulation:
-		endpoint = endpoints.simulations.Input()
-	case JobTypeGraph:
-		endpoint = endpoints.graphs.Input()
-	default:
-		endpoint = endpoints.builds.Input()
-	}
-	req := p.apiRequest(endpoint)
-	req.param("id", id)
+	req, err := p.jobRequest(jobType, opUpload, id)
+	if err != nil {
+		return err
+	}
 	req.jsonBody = false
 
 	f, err := os.Open(srcArchive)
 	if err != nil {
 		return err
 	}
+	defer f.Close()
 
 	resp, err := req.Do("PUT", f)
+	if err != nil {
+		return err
+	}
 	var respJSON struct {
 		Value apiResponse `json:"value"`
 		Error string      `json:"error"`
@@ -469,20 +470,15 @@ func (p *clientImpl) projectID() (string, error) {
 
 func (p *clientImpl) listJobs(jobType string, filters M) ([]jobInfo, error) {
 	limit := filters.Int("limit")
-
-	var endpoint string
-	switch jobType {
-	case JobTypeDeployment:
-		endpoint = endpoints.deployments.String()
-	case JobTypeSimulation, "test":
-		endpoint = endpoints.simulations.String()
-	case JobTypeGraph:
-		endpoint = endpoints.graphs.String()
-	default:
-		endpoint = endpoints.builds.String()
+	status, err := statusFilter(filters)
+	if err != nil {
+		return nil, err
 	}
 
-	request := p.apiRequest(endpoint)
+	request, err := p.jobRequest(jobType, opList, "")
+	if err != nil {
+		return nil, err
+	}
 
 	// if all-projects flag is not set,
 	// and public flag not set, use specific project.
@@ -511,12 +507,13 @@ func (p *clientImpl) listJobs(jobType string, filters M) ([]jobInfo, error) {
 	}
 
 	// handle status filter
-	status := filters.String("status")
 	if status != "" {
-		respJSON.Jobs = jobFilter(respJSON.Jobs).Filter("status", status)
+		respJSON.Jobs = jobFilter(respJSON.Jobs).Filter("status", string(status))
 	}
 
-	sort.Sort(jobSorter(respJSON.Jobs))
+	if err := sortJobs(respJSON.Jobs, filters.String("sort"), filters.Bool("reverse")); err != nil {
+		return nil, err
+	}
 	if limit > 0 && limit < len(respJSON.Jobs) {
 		respJSON.Jobs = respJSON.Jobs[:limit]
 	}
@@ -540,17 +537,10 @@ func (p *clientImpl) listGraphs(filters M) ([]jobInfo, error) {
 }
 
 func (p *clientImpl) stopJob(eventType string, id string) error {
-	var endpoint string
-	switch eventType {
-	case JobTypeSimulation:
-		endpoint = endpoints.simulations.Events()
-	case JobTypeDeployment:
-		endpoint = endpoints.deployments.Events()
-	default:
-		endpoint = endpoints.builds.Events()
-	}
-	req := p.apiRequest(endpoint)
-	req.param("id", id)
+	req, err := p.jobRequest(eventType, opStop, id)
+	if err != nil {
+		return err
+	}
 	reqBody := M{"status": StatusTerminating}
 	resp, err := req.Do("POST", reqBody)
 	if err != nil {
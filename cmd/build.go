@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,7 @@ import (
 	"github.com/ReconfigureIO/cobra"
 	"github.com/ReconfigureIO/reco"
 	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
 )
 
 var (
@@ -16,10 +18,30 @@ var (
 		wait    bool
 		force   bool
 		message string
+		vendor  bool
+		output  string
 	}{
 		wait: true,
 	}
 
+	budgetVars = struct {
+		reportFile string
+		budgetFile string
+		max        map[string]*string
+		minFmax    string
+	}{
+		max: make(map[string]*string),
+	}
+
+	trendVars = struct {
+		metric string
+		last   int
+		output string
+	}{
+		metric: "lut",
+		last:   30,
+	}
+
 	// buildCmd represents the upload command
 	buildCmdStart = &cobra.Command{
 		Use:     "run",
@@ -39,7 +61,7 @@ var (
 		Long:    fmt.Sprintf("Stream logs for a build previously started with 'reco build run'."),
 		PreRun:  buildLogPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Build().Log(args[0], os.Stdout); err != nil {
+			if err := tool.Build().Log(resolveID(tool.Build(), args[0]), os.Stdout); err != nil {
 				exitWithError(err)
 			}
 		},
@@ -49,8 +71,34 @@ var (
 		Use:     fmt.Sprintf("report [build_ID]"),
 		Aliases: []string{"reports"},
 		Short:   fmt.Sprintf("View reports for a completed build"),
-		Long:    fmt.Sprintf("View reports for a completed build, containing area and resource utilisation figures."),
-		Run:     openReport,
+		Long: fmt.Sprintf(`View reports for a completed build, containing area and resource utilisation figures.
+
+Resource budgets can be checked against the report with flags such as
+--max-lut 80%% --max-bram 70%% --min-fmax 250MHz, or declared in a %s file
+in the source directory:
+
+  max:
+    lut: 80%%
+    bram: 70%%
+  min_fmax: 250MHz
+
+The command exits with an error if any budget is exceeded.`, reco.BudgetFile),
+		Run: openReport,
+	}
+
+	buildCmdReportDiff = &cobra.Command{
+		Use:   "diff <build_ID> <build_ID>",
+		Short: "Compare the reports of two completed builds",
+		Long:  "Compare the reports of two completed builds, showing the change in FPGA area for each resource.",
+		Run:   diffReports,
+	}
+
+	buildCmdTrend = &cobra.Command{
+		Use:   "trend",
+		Short: "View a report metric across recent builds",
+		Long: `View a report metric across the last completed builds of the project, oldest first.
+Reports are cached locally, so repeated trends only fetch reports of new builds.`,
+		Run: buildTrend,
 	}
 
 	buildLogPreRun = func(cmd *cobra.Command, args []string) {
@@ -60,37 +108,55 @@ var (
 	}
 
 	buildCmdStop = &cobra.Command{
-		Use:     "stop [build_ID]",
+		Use:     "stop [build_ID...]",
 		Aliases: []string{"s", "stp", "stops"},
 		Short:   fmt.Sprintf("Stop a build"),
-		Long:    fmt.Sprintf("Stop a build previously started with 'reco build run'"),
-		PreRun:  buildStopPreRun,
+		Long: fmt.Sprintf(`Stop builds previously started with 'reco build run'.
+Several builds can be stopped at once by ID, or selected with --all, --status
+and --older-than e.g. 'reco build stop --all --status started'. The builds to
+stop are listed for confirmation, unless --yes is given.`),
+		PreRun: stopPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Build().Stop(args[0]); err != nil {
+			if isBulkStop(args) {
+				bulkStop("build", tool.Build(), args)
+				return
+			}
+			if err := tool.Build().Stop(resolveID(tool.Build(), args[0])); err != nil {
 				exitWithError(err)
 			}
 			logger.Std.Printf("build stopped successfully")
 		},
 	}
-
-	buildStopPreRun = func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			exitWithError("ID required")
-		}
-	}
 )
 
 func init() {
 	buildCmdStart.PersistentFlags().BoolVarP(&buildVars.wait, "wait", "w", buildVars.wait, "Wait for the build to complete. If wait=false, logs will only be displayed up to where the build is started and assigned its unique ID. Use 'reco build list' to check the status of your builds")
 	buildCmdStart.PersistentFlags().BoolVarP(&buildVars.force, "force", "f", buildVars.force, "Force a build to start. Ignore source code validation")
-	buildCmdStart.PersistentFlags().StringVarP(&buildVars.message, "message", "m", buildVars.message, "Add a message to describe this build's purpose")
+	buildCmdStart.PersistentFlags().StringVarP(&buildVars.message, "message", "m", buildVars.message, "Add a message to describe this build's purpose. Defaults to <branch>@<commit> in a git repository")
+	buildCmdStart.PersistentFlags().BoolVar(&buildVars.vendor, "vendor", buildVars.vendor, "Include dependencies resolved from your local Go module cache or GOPATH in the uploaded source. Missing dependencies are reported before upload")
+
+	addStopFlags(buildCmdStop, "build")
+
+	buildCmdReport.PersistentFlags().StringVarP(&buildVars.output, "output", "o", buildVars.output, "Output format: table, json, yaml, csv or tsv")
+	buildCmdReport.AddCommand(buildCmdReportDiff)
+	buildCmdReport.Flags().StringVar(&budgetVars.reportFile, "file", budgetVars.reportFile, "Read the report from a saved file instead of the platform e.g. the output of 'reco build report <build_ID> --output json'")
+	buildCmdReport.Flags().StringVar(&budgetVars.budgetFile, "budget", budgetVars.budgetFile, "Budget file to check the report against (default \""+reco.BudgetFile+"\" in the source directory, if present)")
+	for _, resource := range reco.BuildResources {
+		budgetVars.max[resource] = buildCmdReport.Flags().String("max-"+resource, "", "Fail if "+resource+" utilisation exceeds this percentage e.g. 80%")
+	}
+	buildCmdReport.Flags().StringVar(&budgetVars.minFmax, "min-fmax", budgetVars.minFmax, "Fail if the clock frequency is below this e.g. 250MHz")
+
+	buildCmdTrend.Flags().StringVar(&trendVars.metric, "metric", trendVars.metric, "Metric to show: "+strings.Join(reco.TrendMetrics, ", ")+". Resources are shown as utilisation percentages, fmax in MHz")
+	buildCmdTrend.Flags().IntVar(&trendVars.last, "last", trendVars.last, "Number of most recent completed builds to include")
+	buildCmdTrend.Flags().StringVarP(&trendVars.output, "output", "o", trendVars.output, "Output format: table, json, yaml, csv or tsv")
 
 	buildCmd := genDevCommand("build", "build", "b", "builds")
-	buildCmd.AddCommand(genListSubcommand("builds", tool.Build()))
+	buildCmd.AddCommand(genListSubcommand("builds", func() lister { return tool.Build() }))
 	buildCmd.AddCommand(buildCmdLog)
 	buildCmd.AddCommand(buildCmdStop)
 	buildCmd.AddCommand(buildCmdStart)
 	buildCmd.AddCommand(buildCmdReport)
+	buildCmd.AddCommand(buildCmdTrend)
 	buildCmd.PersistentFlags().StringVar(&project, "project", project, "Project to use. If unset, the active project is used")
 
 	RootCmd.AddCommand(buildCmd)
@@ -101,13 +167,22 @@ func startBuild(cmd *cobra.Command, args []string) {
 		exitWithError(errInvalidSourceDirectory)
 	}
 
-	id, err := tool.Build().Start(reco.Args{srcDir, buildVars.wait, buildVars.message})
+	wait := reco.WaitNone
+	if buildVars.wait {
+		wait = reco.WaitLog
+	}
+	id, err := tool.Build().StartBuild(reco.BuildOptions{
+		SourceDir: srcDir,
+		Message:   buildVars.message,
+		Vendor:    buildVars.vendor,
+		Wait:      wait,
+	})
 	if err != nil {
 		exitWithError(err)
 	}
 
 	status := tool.Build().Status(id)
-	logger.Std.Println("Build ID: " + id + " Status: " + strings.Title(status))
+	logger.Std.Println("Build ID: " + id + " Status: " + strings.Title(strings.ToLower(string(status))))
 }
 
 func validBuildDir(srcDir string) bool {
@@ -156,14 +231,173 @@ func hasMain(dir string) bool {
 }
 
 func openReport(_ *cobra.Command, args []string) {
-	if len(args) != 1 {
+	if len(args) != 1 && budgetVars.reportFile == "" {
 		exitWithError("ID required")
 	}
 
-	report, err := tool.Build().(reco.BuildReporter).Report(args[0])
+	format, err := printer.ParseFormat(buildVars.output)
 	if err != nil {
 		exitWithError(err)
 	}
 
-	logger.Std.Println(report)
+	budget, err := reportBudget()
+	if err != nil {
+		exitWithError(err)
+	}
+
+	var report reco.BuildReport
+	if budgetVars.reportFile != "" {
+		report, err = reco.LoadBuildReport(budgetVars.reportFile)
+	} else {
+		report, err = buildReporter().Report(resolveID(tool.Build(), args[0]))
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+
+	switch format {
+	case printer.FormatJSON:
+		b, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			exitWithError(err)
+		}
+		logger.Std.Println(string(b))
+	case printer.FormatTable:
+		printBuildReport(report)
+	default:
+		if err := printer.FprintFormat(os.Stdout, report.Table(), format); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if budget.Empty() {
+		return
+	}
+	violations := budget.Check(report)
+	if len(violations) == 0 {
+		logger.Info.Println("All budget constraints met")
+		return
+	}
+	// keep stdout parsable for structured output.
+	logger.Info.Println("Budget exceeded:")
+	printer.Fprint(os.Stderr, reco.ViolationsTable(violations))
+	exitWithError(fmt.Errorf("%d budget constraint(s) violated", len(violations)))
+}
+
+// reportBudget returns the budget from the budget file, if any,
+// with constraints set by flags taking precedence.
+func reportBudget() (reco.Budget, error) {
+	var budget reco.Budget
+	var err error
+	if budgetVars.budgetFile != "" {
+		budget, err = reco.LoadBudget(budgetVars.budgetFile)
+	} else if file := filepath.Join(srcDir, reco.BudgetFile); fileExists(file) {
+		budget, err = reco.LoadBudget(file)
+	}
+	if err != nil {
+		return budget, err
+	}
+
+	for resource, max := range budgetVars.max {
+		if *max == "" {
+			continue
+		}
+		if err := budget.SetMax(resource, *max); err != nil {
+			return budget, err
+		}
+	}
+	if budgetVars.minFmax != "" {
+		if err := budget.SetMinFmax(budgetVars.minFmax); err != nil {
+			return budget, err
+		}
+	}
+	return budget, nil
+}
+
+func buildReporter() reco.BuildReporter {
+	reporter, ok := tool.Build().(reco.BuildReporter)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	return reporter
+}
+
+func printBuildReport(report reco.BuildReport) {
+	if report.PartName != "" {
+		logger.Std.Println("Part: ", report.PartName)
+	}
+	if err := printer.Fprint(os.Stdout, report.Table()); err != nil {
+		exitWithError(err)
+	}
+	if report.ClockFrequency > 0 {
+		logger.Std.Printf("Clock frequency: %.2f MHz", report.ClockFrequency)
+	}
+	if report.TimingSlack != 0 {
+		logger.Std.Printf("Timing slack: %.3f ns", report.TimingSlack)
+	}
+}
+
+func diffReports(_ *cobra.Command, args []string) {
+	if len(args) != 2 {
+		exitWithError("two build IDs required")
+	}
+
+	format, err := printer.ParseFormat(buildVars.output)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	reporter := buildReporter()
+	before, err := reporter.Report(resolveID(tool.Build(), args[0]))
+	if err != nil {
+		exitWithError(err)
+	}
+	after, err := reporter.Report(resolveID(tool.Build(), args[1]))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if format == printer.FormatTable {
+		logger.Std.Printf("Comparing build %s (before) with build %s (after)", args[0], args[1])
+	}
+	if err := printer.FprintFormat(os.Stdout, before.DiffTable(after), format); err != nil {
+		exitWithError(err)
+	}
+}
+
+func buildTrend(_ *cobra.Command, _ []string) {
+	if trendVars.last < 1 {
+		exitWithError("--last must be at least 1")
+	}
+	format, err := printer.ParseFormat(trendVars.output)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	trend, err := buildReporter().Trend(trendVars.metric, trendVars.last)
+	if err != nil {
+		exitWithError(err)
+	}
+	if format != printer.FormatTable {
+		if err := printer.FprintFormat(os.Stdout, trend.Table(), format); err != nil {
+			exitWithError(err)
+		}
+		return
+	}
+
+	if len(trend.Points) == 0 {
+		logger.Std.Println("No completed builds with reports found")
+		return
+	}
+	values := trend.Values()
+	first, latest := values[0], values[len(values)-1]
+	unit := "%"
+	if trend.Metric == "fmax" {
+		unit = " MHz"
+	}
+	logger.Std.Printf("%s over %d builds: %s  %.2f%s -> %.2f%s",
+		trend.Metric, len(values), printer.Sparkline(values), first, unit, latest, unit)
+	if err := printer.Fprint(os.Stdout, trend.Table()); err != nil {
+		exitWithError(err)
+	}
 }
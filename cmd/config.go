@@ -30,6 +30,7 @@ func exitWithError(err interface{}) {
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "Error:", err)
 	}
+	closeTool()
 	os.Exit(1)
 }
 
@@ -40,5 +41,6 @@ func exitWithUsage(cmd *cobra.Command, err interface{}) {
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, cmd.UsageString())
 	}
+	closeTool()
 	os.Exit(1)
 }
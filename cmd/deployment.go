@@ -4,23 +4,35 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/ReconfigureIO/cobra"
 	"github.com/ReconfigureIO/reco"
 	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
 )
 
 var (
 	deploymentVars = struct {
-		wait string
+		wait    string
+		ports   repeatedFlag
+		locals  repeatedFlag
+		probe   reco.Probe
+		env     repeatedFlag
+		envFile repeatedFlag
+		inputs  repeatedFlag
+		ttl     time.Duration
+		dryRun  bool
 	}{
-		wait: "true",
+		wait:  "true",
+		probe: reco.DefaultProbe(),
 	}
 
 	errorDeploymentNotFound = errors.New("No deployment with that ID could be found. Run 'reco deploy list' to view available deployments")
 
 	deploymentCmdStart = &cobra.Command{
-		Use:     "run [flags] <build_ID> <your_cmd> -- [args]",
+		Use:     "run [flags] <build_ID|build_ref> <your_cmd> -- [args]",
 		Aliases: []string{"r", "start", "starts"},
 		Short:   "Deploy a build image and command to an F1 instance",
 		Long: `Deploy a build image and run a command from that build on an F1 instance.
@@ -45,6 +57,18 @@ of your arguments may conflict with this command. If this is the case,
 use "--" to specify that all further arguments should be provided to
 your command. The two forms are equivalent:
 "reco run my-image my-cmd -- 1" and "reco run my-image my-cmd 1"
+
+Build references:
+
+Instead of a build ID, a completed build of the project can be referred to as:
+
+  latest            the newest completed build
+  latest@<branch>   the newest completed build of a git branch
+  <ref>~N           the Nth completed build before ref e.g. latest~1 or build~2
+
+Builds started from a git repository record their branch locally, and their
+message defaults to <branch>@<commit>. Other builds match a branch if it is
+a word of their message.
 	`,
 		Run: startDeployment,
 	}
@@ -52,9 +76,29 @@ your command. The two forms are equivalent:
 	deploymentCmdConnect = &cobra.Command{
 		Use:     "connect <deploy_ID>",
 		Aliases: []string{"c", "connects"},
-		Short:   "Connects to a deployment in your browser",
-		Long:    "Connects to a running deployment on port 80 using your default web browser",
-		Run:     connectDeployment,
+		Short:   "Connects to a deployment in your browser, or forwards ports to it",
+		Long: `Connects to a running deployment on port 80 using your default web browser.
+The browser is opened once the deployment passes its readiness probe, by
+default a TCP connection to port 80 e.g. '--probe http --probe-path /health
+--probe-status 200' waits for a health check to succeed instead.
+
+With --port, local ports are forwarded to ports of the deployment instead,
+until interrupted e.g. 'reco deploy connect <deploy_ID> --port 8080 --local 9000'
+forwards localhost:9000 to port 8080 of the deployment. --port can be repeated,
+with each --local pairing with the --port in the same position, or given as
+local:remote. Connection stats are printed on exit.`,
+		Run: connectDeployment,
+	}
+
+	deploymentCmdReap = &cobra.Command{
+		Use:   "reap",
+		Short: "Stop deployments past their time to live",
+		Long: `Stop started deployments past the time to live given with 'reco deploy run --ttl'.
+Deadlines are recorded in the reco config directory of your user, so reap stops
+deployments started from any directory. It does not ask for confirmation and is
+safe to run from cron e.g. '*/5 * * * * reco deploy reap'. It exits with an error
+if any deployment could not be stopped.`,
+		Run: reapDeployments,
 	}
 
 	deploymentCmdLog = &cobra.Command{
@@ -64,7 +108,7 @@ your command. The two forms are equivalent:
 		Long:    fmt.Sprintf("Stream logs for a deployment previously started with 'reco deploy run'."),
 		PreRun:  deploymentLogPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Deployment().Log(args[0], os.Stdout); err != nil {
+			if err := tool.Deployment().Log(resolveID(tool.Deployment(), args[0]), os.Stdout); err != nil {
 				exitWithError(interpretErrorDeployment(err))
 			}
 		},
@@ -77,35 +121,49 @@ your command. The two forms are equivalent:
 	}
 
 	deploymentCmdStop = &cobra.Command{
-		Use:     "stop [deployment_ID]",
+		Use:     "stop [deployment_ID...]",
 		Aliases: []string{"s", "stp", "stops"},
 		Short:   fmt.Sprintf("Stop a deployment"),
-		Long:    fmt.Sprintf("Stop a deployment previously started with 'reco deploy run'"),
-		PreRun:  deploymentStopPreRun,
+		Long: fmt.Sprintf(`Stop deployments previously started with 'reco deploy run'.
+Several deployments can be stopped at once by ID, or selected with --all, --status
+and --older-than e.g. 'reco deploy stop --all --status started'. The deployments to
+stop are listed for confirmation, unless --yes is given.`),
+		PreRun: stopPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Deployment().Stop(args[0]); err != nil {
+			if isBulkStop(args) {
+				bulkStop("deployment", tool.Deployment(), args)
+				return
+			}
+			if err := tool.Deployment().Stop(resolveID(tool.Deployment(), args[0])); err != nil {
 				exitWithError(interpretErrorDeployment(err))
 			}
 			logger.Std.Printf("deployment stopped successfully")
 		},
 	}
-
-	deploymentStopPreRun = func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			exitWithError("ID required")
-		}
-	}
 )
 
 func init() {
-	deploymentCmdStart.PersistentFlags().StringVarP(&deploymentVars.wait, "wait", "w", deploymentVars.wait, "Wait for the run to complete. If false, it only starts the command without waiting for it to complete")
+	deploymentCmdStart.PersistentFlags().StringVarP(&deploymentVars.wait, "wait", "w", deploymentVars.wait, "Wait for the run to complete. If false, it only starts the command without waiting for it to complete. If http, it waits until the readiness probe passes")
+	deploymentCmdStart.Flags().Var(&deploymentVars.env, "env", "Environment variable of the command as KEY=VALUE. Can be repeated. Values of keys containing KEY, TOKEN, SECRET, PASSWORD, PASSWD, CREDENTIAL or AUTH are masked in output")
+	deploymentCmdStart.Flags().Var(&deploymentVars.envFile, "env-file", "File of KEY=VALUE environment variables of the command e.g. .env. Can be repeated. --env takes precedence")
+	deploymentCmdStart.Flags().Var(&deploymentVars.inputs, "input", "Small input file uploaded with the deployment, available in the working directory of the command. Can be repeated. Limited to 10MB in total")
+	deploymentCmdStart.Flags().DurationVar(&deploymentVars.ttl, "ttl", deploymentVars.ttl, "Time to live of the deployment e.g. 30m. Deployments past their time to live are stopped by 'reco deploy reap'")
+	addProbeFlags(deploymentCmdStart)
+	deploymentCmdReap.Flags().BoolVar(&deploymentVars.dryRun, "dry-run", deploymentVars.dryRun, "List the deployments past their time to live without stopping them")
+	addProbeFlags(deploymentCmdConnect)
+
+	addStopFlags(deploymentCmdStop, "deployment")
+
+	deploymentCmdConnect.Flags().Var(&deploymentVars.ports, "port", "Deployment port to forward a local port to, or local:remote. Can be repeated")
+	deploymentCmdConnect.Flags().Var(&deploymentVars.locals, "local", "Local port to forward to the --port in the same position. Defaults to the deployment port")
 
 	deploymentCmd := genDevCommand("deploy", "deployment", "d", "dep", "deps", "deployments", "deployment")
-	deploymentCmd.AddCommand(genListSubcommand("deployments", tool.Deployment()))
+	deploymentCmd.AddCommand(genListSubcommand("deployments", func() lister { return tool.Deployment() }))
 	deploymentCmd.AddCommand(deploymentCmdLog)
 	deploymentCmd.AddCommand(deploymentCmdStop)
 	deploymentCmd.AddCommand(deploymentCmdStart)
 	deploymentCmd.AddCommand(deploymentCmdConnect)
+	deploymentCmd.AddCommand(deploymentCmdReap)
 	deploymentCmd.PersistentFlags().StringVar(&project, "project", project, "Project to use. If unset, the active project is used")
 
 	RootCmd.AddCommand(deploymentCmd)
@@ -123,8 +181,35 @@ func startDeployment(cmd *cobra.Command, args []string) {
 	} else if len(args) > 2 {
 		commandArgs = args[2:]
 	}
-	out, err := tool.Deployment().Start(reco.Args{image, command, commandArgs, deploymentVars.wait})
+	wait, err := reco.ParseWaitMode(deploymentVars.wait, reco.WaitLog, reco.WaitNone, reco.WaitHTTP)
 	if err != nil {
+		exitWithUsage(cmd, err)
+	}
+	env := make(map[string]string)
+	for _, file := range deploymentVars.envFile {
+		if err := reco.LoadEnvFile(env, file); err != nil {
+			exitWithError(err)
+		}
+	}
+	for _, v := range deploymentVars.env {
+		if err := reco.ParseEnv(env, v); err != nil {
+			exitWithError(err)
+		}
+	}
+	out, err := tool.Deployment().StartDeployment(reco.DeploymentOptions{
+		Build:   image,
+		Command: command,
+		Args:    commandArgs,
+		Wait:    wait,
+		Probe:   deploymentVars.probe,
+		Env:     env,
+		Inputs:  deploymentVars.inputs,
+		TTL:     deploymentVars.ttl,
+	})
+	if err != nil {
+		if out != "" {
+			logger.Info.Println("Deployment ID: ", out)
+		}
 		exitWithError(interpretErrorDeployment(err))
 	}
 	logger.Std.Println(out)
@@ -134,9 +219,87 @@ func connectDeployment(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
 		exitWithError("deployment ID required")
 	}
-	if err := tool.Deployment().(reco.DeploymentProxy).Connect(args[0], true); err != nil {
+	id := resolveID(tool.Deployment(), args[0])
+	if len(deploymentVars.ports) > 0 || len(deploymentVars.locals) > 0 {
+		forwardPorts(id)
+		return
+	}
+	proxy, ok := tool.Deployment().(reco.DeploymentProxy)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	if err := proxy.Connect(id, deploymentVars.probe, true); err != nil {
+		exitWithError(interpretErrorDeployment(err))
+	}
+}
+
+// forwardPorts forwards local ports to deployment id until interrupted,
+// then prints the connection stats.
+func forwardPorts(id string) {
+	mappings, err := reco.ParsePortMappings(deploymentVars.ports, deploymentVars.locals)
+	if err != nil {
+		exitWithError(err)
+	}
+	forwarder, ok := tool.Deployment().(reco.PortForwarder)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	stop := make(chan struct{})
+	go func() {
+		<-interrupt
+		// a second Ctrl-C kills reco, if closing hangs.
+		signal.Stop(interrupt)
+		close(stop)
+	}()
+
+	logger.Info.Println("Press Ctrl-C to stop forwarding")
+	stats, err := forwarder.Forward(id, mappings, stop)
+	if err != nil {
 		exitWithError(interpretErrorDeployment(err))
 	}
+	if err := printer.Fprint(os.Stdout, reco.ForwardStatsTable(stats)); err != nil {
+		exitWithError(err)
+	}
+}
+
+// addProbeFlags adds the readiness probe flags to a command.
+func addProbeFlags(cmd *cobra.Command) {
+	probe := &deploymentVars.probe
+	cmd.Flags().StringVar(&probe.Kind, "probe", probe.Kind, "Readiness probe: tcp connects to the probe port, http sends a GET request for the probe path")
+	cmd.Flags().IntVar(&probe.Port, "probe-port", probe.Port, "Deployment port to probe")
+	cmd.Flags().StringVar(&probe.Path, "probe-path", probe.Path, "Path requested by http probes")
+	cmd.Flags().IntVar(&probe.Status, "probe-status", probe.Status, "Expected status of http probes. Any 2xx or 3xx status if unset")
+	cmd.Flags().StringVar(&probe.Body, "probe-body", probe.Body, "Regular expression the response body of http probes must match")
+	cmd.Flags().DurationVar(&probe.Delay, "probe-delay", probe.Delay, "Wait before the first probe, once the deployment has an address")
+	cmd.Flags().DurationVar(&probe.Interval, "probe-interval", probe.Interval, "Wait between probes")
+	cmd.Flags().DurationVar(&probe.Timeout, "probe-timeout", probe.Timeout, "Time to wait for the deployment to be ready once it has started, excluding time queued. 0 waits until the deployment finishes")
+}
+
+func reapDeployments(_ *cobra.Command, _ []string) {
+	reaper, ok := tool.Deployment().(reco.DeploymentReaper)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	results, err := reaper.Reap(deploymentVars.dryRun)
+	if err != nil {
+		exitWithError(err)
+	}
+	if len(results) == 0 {
+		logger.Std.Println("no deployments past their time to live")
+		return
+	}
+	if err := printer.Fprint(os.Stdout, reco.ReapTable(results)); err != nil {
+		exitWithError(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			exitWithError("some deployments could not be stopped")
+		}
+	}
 }
 
 func interpretErrorDeployment(err error) error {
@@ -44,13 +44,19 @@ This attempts to use your default pdf viewer to open the graph.
 	Run: openGraph,
 }
 
+var graphVars struct {
+	vendor bool
+}
+
 var errInvalidGraphSourceDirectory = errors.New("Invalid source directory. Directory must have a main.go file")
 
 func init() {
+	graphCmdGenerate.PersistentFlags().BoolVar(&graphVars.vendor, "vendor", graphVars.vendor, "Include dependencies resolved from your local Go module cache or GOPATH in the uploaded source. Missing dependencies are reported before upload")
+
 	graphCmd.AddCommand(
 		graphCmdGenerate,
 		graphCmdOpen,
-		genListSubcommand("graphs", tool.Graph()),
+		genListSubcommand("graphs", func() lister { return tool.Graph() }),
 	)
 	graphCmd.PersistentFlags().StringVar(&project, "project", project, "Project to use. If unset, the active project is used")
 
@@ -61,7 +67,7 @@ func generateGraph(cmd *cobra.Command, args []string) {
 	if !validGraphDir(srcDir) {
 		exitWithError(errInvalidGraphSourceDirectory)
 	}
-	id, err := tool.Graph().Generate(reco.Args{srcDir})
+	id, err := tool.Graph().Generate(reco.Args{srcDir, false, graphVars.vendor})
 	if err != nil {
 		exitWithError(interpretErrorGraph(err))
 	}
@@ -73,7 +79,7 @@ func openGraph(_ *cobra.Command, args []string) {
 	if len(args) == 0 {
 		exitWithError("ID required")
 	}
-	file, err := tool.Graph().Open(args[0])
+	file, err := tool.Graph().Open(resolveID(tool.Graph(), args[0]))
 	if err != nil {
 		exitWithError(interpretErrorGraph(err))
 	}
@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+
+	"github.com/ReconfigureIO/reco"
 )
 
 func getConfigDir() string {
@@ -23,7 +26,47 @@ func getConfigDir() string {
 	return filepath.Join(dir, "reco")
 }
 
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
+
 func getCurrentDir() string {
 	dir, _ := os.Getwd()
 	return dir
 }
+
+// repeatedFlag is a string flag that can be repeated. Unlike
+// string slice flags, values are not split on commas.
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, " ")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func (r *repeatedFlag) Type() string {
+	return "stringArray"
+}
+
+// resolveID returns the ID of the job of a job type starting with
+// id, git-style. Unknown IDs are returned as is, so the job type
+// reports them as not found.
+func resolveID(job interface{}, id string) string {
+	resolver, ok := job.(reco.IDResolver)
+	if !ok {
+		return id
+	}
+	fullID, err := resolver.ResolveID(id)
+	if err == reco.ErrNotFound {
+		return id
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	return fullID
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ReconfigureIO/cobra"
+	"github.com/ReconfigureIO/reco"
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+var errorJobNotFound = errors.New("No build, simulation, deployment or graph with that ID could be found")
+
+// stopVars are the flags of stop commands selecting several jobs.
+var stopVars struct {
+	all       bool
+	status    string
+	olderThan time.Duration
+	yes       bool
+}
+
+var (
+	jobsCmdLog = &cobra.Command{
+		Use:              "logs <ID>",
+		Aliases:          []string{"log"},
+		Short:            "Stream logs for a build, simulation or deployment",
+		Long:             "Stream logs for a build, simulation or deployment. The job type is detected from the ID, which may be a unique prefix.",
+		PersistentPreRun: initializeCmd,
+		Run:              jobLog,
+	}
+
+	jobsCmdStop = &cobra.Command{
+		Use:              "stop <ID>",
+		Short:            "Stop a build, simulation or deployment",
+		Long:             "Stop a build, simulation or deployment. The job type is detected from the ID, which may be a unique prefix.",
+		PersistentPreRun: initializeCmd,
+		Run:              jobStop,
+	}
+)
+
+func init() {
+	RootCmd.AddCommand(jobsCmdLog)
+	RootCmd.AddCommand(jobsCmdStop)
+}
+
+// findJob returns the job type and complete ID of the job with ID
+// or ID prefix id.
+func findJob(id string) (string, reco.Job, string) {
+	finder, ok := tool.(reco.JobFinder)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	jobType, fullID, err := finder.FindJob(id)
+	if err == reco.ErrNotFound {
+		exitWithError(errorJobNotFound)
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+	var job reco.Job
+	switch jobType {
+	case reco.JobTypeBuild:
+		job = tool.Build()
+	case reco.JobTypeSimulation:
+		job = tool.Test()
+	case reco.JobTypeDeployment:
+		job = tool.Deployment()
+	default:
+		exitWithError("graphs cannot be stopped and have no logs. Run 'reco graph open " + fullID + "' to view the graph")
+	}
+	return jobType, job, fullID
+}
+
+func jobLog(_ *cobra.Command, args []string) {
+	if len(args) == 0 {
+		exitWithError("ID required")
+	}
+	jobType, job, id := findJob(args[0])
+	logger.Info.Printf("streaming logs for %s %s", jobType, id)
+	if err := job.Log(id, os.Stdout); err != nil {
+		exitWithError(err)
+	}
+}
+
+func jobStop(_ *cobra.Command, args []string) {
+	if len(args) == 0 {
+		exitWithError("ID required")
+	}
+	jobType, job, id := findJob(args[0])
+	if err := job.Stop(id); err != nil {
+		exitWithError(err)
+	}
+	logger.Std.Printf("%s %s stopped successfully", jobType, id)
+}
+
+// addStopFlags adds the flags selecting several jobs to a stop command.
+func addStopFlags(cmd *cobra.Command, jobType string) {
+	cmd.Flags().BoolVar(&stopVars.all, "all", stopVars.all, fmt.Sprintf("Stop all unfinished %ss of the project", jobType))
+	cmd.Flags().StringVar(&stopVars.status, "status", stopVars.status, fmt.Sprintf("Only stop %ss with this status e.g. started", jobType))
+	cmd.Flags().DurationVar(&stopVars.olderThan, "older-than", stopVars.olderThan, fmt.Sprintf("Only stop %ss started longer ago than this e.g. 2h", jobType))
+	cmd.Flags().BoolVarP(&stopVars.yes, "yes", "y", stopVars.yes, "Stop without asking for confirmation")
+}
+
+// stopSelectsJobs checks if the stop flags select jobs without IDs.
+func stopSelectsJobs() bool {
+	return stopVars.all || stopVars.status != "" || stopVars.olderThan > 0
+}
+
+// stopPreRun checks stop commands are given IDs or select jobs by flags.
+func stopPreRun(cmd *cobra.Command, args []string) {
+	if len(args) == 0 && !stopSelectsJobs() {
+		exitWithError("ID required. Use --all, --status or --older-than to stop several jobs")
+	}
+}
+
+// isBulkStop checks if a stop command stops more than a single job ID.
+func isBulkStop(args []string) bool {
+	return len(args) != 1 || stopSelectsJobs()
+}
+
+// bulkStop stops the jobs of jobType selected by args and the stop
+// flags, after confirming the jobs to stop.
+func bulkStop(jobType string, job reco.Job, args []string) {
+	stopper, ok := job.(reco.BulkStopper)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	targets, err := stopper.StopTargets(reco.M{
+		"ids":        args,
+		"status":     stopVars.status,
+		"older_than": stopVars.olderThan,
+	})
+	if err != nil {
+		exitWithError(err)
+	}
+	if targets.Empty() {
+		logger.Std.Printf("no %ss to stop", jobType)
+		return
+	}
+	if err := printer.Fprint(os.Stdout, targets); err != nil {
+		exitWithError(err)
+	}
+	if !stopVars.yes {
+		fmt.Printf("Stop %d %s(s)? [y/n] ", len(targets.Body), jobType)
+		if !confirmStop() {
+			return
+		}
+	}
+	ids := make([]string, len(targets.Body))
+	for i := range targets.Body {
+		ids[i] = fmt.Sprint(targets.Value(i, "id"))
+	}
+	results := stopper.StopJobs(ids)
+	if err := printer.Fprint(os.Stdout, reco.StopResultTable(results)); err != nil {
+		exitWithError(err)
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		exitWithError(fmt.Sprintf("%d of %d %s(s) could not be stopped", failed, len(results), jobType))
+	}
+}
+
+// confirmStop reads a yes or no answer from stdin. It exits if stdin
+// has no answer, e.g. when reco is not run from a terminal.
+func confirmStop() bool {
+	for {
+		var response string
+		if _, err := fmt.Scanln(&response); err == io.EOF {
+			fmt.Println()
+			exitWithError("no confirmation given. Use --yes to stop without confirmation")
+		}
+		switch strings.ToLower(response) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		fmt.Println("Please type yes or no and then press enter:")
+	}
+}
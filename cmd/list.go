@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ReconfigureIO/cobra"
 	"github.com/ReconfigureIO/reco"
@@ -11,7 +15,7 @@ import (
 	"github.com/ReconfigureIO/reco/printer"
 )
 
-var listVars struct {
+var listVars = struct {
 	resourceType string
 	table        printer.Table
 	err          error
@@ -21,62 +25,178 @@ var listVars struct {
 	status      string
 	allProjects bool
 	public      bool
+	output      string
+	format      string
+	columns     string
+	sortBy      string
+	reverse     bool
+	watch       bool
+	interval    time.Duration
+}{
+	interval: 10 * time.Second,
 }
 
 type lister interface {
 	List(filter reco.M) (printer.Table, error)
 }
 
-func genListSubcommand(name string, job lister) *cobra.Command {
+// genListSubcommand generates a list command for resources listed
+// by the lister returned by job. job is called when the command runs,
+// once the provider's client is initialised.
+func genListSubcommand(name string, job func() lister) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls", "lst", "lists"},
 		Short:   fmt.Sprintf("List all %s for your current project", name),
 		Long:    fmt.Sprintf("List all %s for your current project - status information, start times and unique IDs will be displayed.", name),
 		Run: func(cmd *cobra.Command, args []string) {
-			filters := reco.M{}
-			if listVars.status != "" {
-				filters["status"] = listVars.status
-			}
-			if listVars.limit != 0 {
-				filters["limit"] = strconv.Itoa(listVars.limit)
-			}
-			if listVars.allProjects {
-				filters["all"] = "1"
-			}
-			if listVars.public {
-				filters["public"] = "1"
-			}
-
 			listVars.resourceType = name
-			listVars.table, listVars.err = job.List(filters)
+			if listVars.watch {
+				watchList(cmd, job())
+				return
+			}
+			listVars.table, listVars.err = job().List(listFilters())
 		},
 		PostRun: listPostRun,
 	}
 	listCmdAddFlags(cmd)
+	cmd.PersistentFlags().StringVar(&listVars.sortBy, "sort-by", listVars.sortBy, "Sort by started (newest first), duration (longest first) or status")
+	cmd.PersistentFlags().BoolVar(&listVars.reverse, "reverse", listVars.reverse, "Reverse the sort order")
+	cmd.PersistentFlags().BoolVar(&listVars.watch, "watch", listVars.watch, "Refresh the list every interval until interrupted with Ctrl-C, highlighting status changes")
+	cmd.PersistentFlags().DurationVar(&listVars.interval, "interval", listVars.interval, "Refresh interval for --watch")
 	return cmd
 }
 
+// listFilters returns the list filters set by flags.
+func listFilters() reco.M {
+	filters := reco.M{}
+	if listVars.status != "" {
+		filters["status"] = listVars.status
+	}
+	if listVars.limit != 0 {
+		filters["limit"] = strconv.Itoa(listVars.limit)
+	}
+	if listVars.allProjects {
+		filters["all"] = "1"
+	}
+	if listVars.public {
+		filters["public"] = "1"
+	}
+	if listVars.sortBy != "" {
+		filters["sort"] = listVars.sortBy
+	}
+	if listVars.reverse {
+		filters["reverse"] = "1"
+	}
+	return filters
+}
+
 var listPostRun = func(cmd *cobra.Command, args []string) {
+	if listVars.watch {
+		return
+	}
 	if listVars.err != nil {
 		exitWithError(listVars.err)
 	}
+	if err := printList(listVars.table, !listVars.noScroll); err != nil {
+		exitWithError(err)
+	}
+}
+
+// printList prints table in the format set by flags. Table output
+// is paged if paged is set.
+func printList(table printer.Table, paged bool) error {
+	format, err := printer.ParseFormat(listVars.output)
+	if err != nil {
+		return err
+	}
+
+	if listVars.format != "" {
+		if format != printer.FormatTable {
+			return errors.New("--format and --output cannot be used together")
+		}
+		return printer.FprintTemplate(os.Stdout, table, listVars.format)
+	}
 
-	if listVars.table.Empty() {
+	if listVars.columns != "" {
+		table, err = table.Select(strings.Split(listVars.columns, ","))
+		if err != nil {
+			return err
+		}
+	}
+
+	// structured output is never paged and prints empty results.
+	if format != printer.FormatTable {
+		return printer.FprintFormat(os.Stdout, table, format)
+	}
+
+	if table.Empty() {
 		logger.Std.Printf("You have no %s.", listVars.resourceType)
-		return
+		return nil
 	}
 
-	var err error
+	if paged {
+		return printer.Print(table)
+	}
+	return printer.Fprint(os.Stdout, table)
+}
 
-	if listVars.noScroll {
-		err = printer.Fprint(os.Stdout, listVars.table)
-	} else {
-		err = printer.Print(listVars.table)
+// watchList lists jobs every interval until interrupted. Jobs whose
+// status changed since the previous refresh are highlighted. Output is
+// redrawn in place on terminals and appended otherwise.
+func watchList(cmd *cobra.Command, job lister) {
+	if listVars.interval < time.Second {
+		exitWithError("--interval must be at least 1s")
 	}
 
-	if err != nil {
-		exitWithError(err)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	redraw := printer.Redrawable()
+	var previous printer.Table
+	for {
+		table, err := job.List(listFilters())
+		if err != nil {
+			// keep watching through transient errors.
+			logger.Error.Println(err)
+		} else {
+			changed := table.Changed(previous, "id", "status")
+			if redraw {
+				printer.ClearScreen(os.Stdout)
+			}
+			logger.Std.Printf("Every %s: %s. Refreshed %s. Press Ctrl-C to exit.",
+				listVars.interval, cmd.CommandPath(), time.Now().Format("15:04:05"))
+			if !printer.Colors {
+				logChanges(table, previous, changed)
+			}
+			if err := printList(table.Highlight(changed), false); err != nil {
+				exitWithError(err)
+			}
+			previous = table
+		}
+
+		select {
+		case <-interrupt:
+			return
+		case <-time.After(listVars.interval):
+		}
+	}
+}
+
+// logChanges logs status changes for output without highlighting.
+func logChanges(table, previous printer.Table, changed []int) {
+	statuses := make(map[string]interface{})
+	for i := range previous.Body {
+		statuses[fmt.Sprint(previous.Value(i, "id"))] = previous.Value(i, "status")
+	}
+	for _, i := range changed {
+		id, status := fmt.Sprint(table.Value(i, "id")), table.Value(i, "status")
+		if prev, ok := statuses[id]; ok {
+			logger.Std.Printf("%s: %v -> %v", id, prev, status)
+		} else {
+			logger.Std.Printf("%s: %v", id, status)
+		}
 	}
 }
 
@@ -86,4 +206,7 @@ func listCmdAddFlags(listCmd *cobra.Command) {
 	listCmd.PersistentFlags().StringVar(&listVars.status, "status", listVars.status, "Filter result by status: completed, errored, timed-out etc")
 	listCmd.PersistentFlags().BoolVar(&listVars.allProjects, "all-projects", listVars.allProjects, "List items for all projects, not just the active project")
 	listCmd.PersistentFlags().BoolVar(&listVars.public, "public", listVars.public, "Only list publically available items")
+	listCmd.PersistentFlags().StringVarP(&listVars.output, "output", "o", listVars.output, "Output format: table, json, yaml, csv or tsv. Structured formats show raw values such as RFC 3339 times and durations in seconds")
+	listCmd.PersistentFlags().StringVar(&listVars.format, "format", listVars.format, "Print each item using a Go template e.g. '{{.ID}} {{.Status}}'. Fields are named after columns e.g. ID, BuildID, Status, Started, Duration, Message")
+	listCmd.PersistentFlags().StringVar(&listVars.columns, "columns", listVars.columns, "Comma separated list of columns to display e.g. id,status,duration,message")
 }
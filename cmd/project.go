@@ -1,10 +1,7 @@
 package cmd
 
 import (
-	"strconv"
-
 	"github.com/ReconfigureIO/cobra"
-	"github.com/ReconfigureIO/reco"
 	"github.com/ReconfigureIO/reco/logger"
 )
 
@@ -66,6 +63,7 @@ func init() {
 		projectCmdCreate,
 		projectCmdList,
 	)
+	listCmdAddFlags(projectCmdList)
 
 	RootCmd.AddCommand(projectCmd)
 }
@@ -97,21 +95,6 @@ func getProject(cmd *cobra.Command, args []string) {
 }
 
 func listProject(cmd *cobra.Command, args []string) {
-	filters := reco.M{}
-	if listVars.status != "" {
-		filters["status"] = listVars.status
-	}
-	if listVars.limit != 0 {
-		filters["limit"] = strconv.Itoa(listVars.limit)
-	}
-	if listVars.allProjects {
-		filters["all"] = "1"
-	}
-	if listVars.public {
-		filters["public"] = "1"
-	}
-
 	listVars.resourceType = "project"
-	listVars.table, listVars.err = tool.Project().List(filters)
-	listCmdAddFlags(cmd)
+	listVars.table, listVars.err = tool.Project().List(listFilters())
 }
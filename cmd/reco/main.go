@@ -1,8 +1,23 @@
 package main
 
-import "github.com/ReconfigureIO/reco/cmd"
+import (
+	"fmt"
+	"os"
+
+	"github.com/ReconfigureIO/reco"
+	"github.com/ReconfigureIO/reco/cmd"
+)
 
 func main() {
+	// supervise a local job started with the local provider.
+	if file := os.Getenv(reco.LocalJobEnv); file != "" {
+		if err := reco.RunLocalJob(file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// set build info
 	cmd.BuildInfo.Version = version
 	cmd.BuildInfo.BuildTime = buildTime
@@ -2,20 +2,25 @@ package cmd
 
 import (
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/ReconfigureIO/cobra"
 	"github.com/ReconfigureIO/reco"
+	"github.com/ReconfigureIO/reco/printer"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var noColor bool
 var provider string
 var project string
 var srcDir string
 var tool reco.Client = reco.NewClient()
 
+var errUnsupportedProvider = errors.New("That command is not supported by the provider")
+
 var errInvalidSourceDirectory = errors.New("invalid source directory. Directory and all cmd/<directory> subdirectories must have a main.go file")
 
 // RootCmd represents the base command when called without any subcommands
@@ -28,22 +33,40 @@ var RootCmd = &cobra.Command{
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	err := RootCmd.Execute()
+	closeTool()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// closeTool closes the provider client, for providers running a
+// process such as plugins.
+func closeTool() {
+	if closer, ok := tool.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
 func init() {
 	// template
 	RootCmd.SetUsageTemplate(usageTemplate)
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", `Config file (default "`+filepath.Join(getConfigDir(), "reco.yml")+`")`)
-	RootCmd.PersistentFlags().StringVar(&provider, "provider", "", "Service provider")
+	RootCmd.PersistentFlags().StringVar(&provider, "provider", "", `Service provider: "platform" (default), "local" to run simulations as local processes, or a plugin. Defaults to the provider set in config`)
 	RootCmd.PersistentFlags().StringVarP(&srcDir, "source", "s", "", `Source directory (default is current directory "`+getCurrentDir()+`")`)
+	RootCmd.PersistentFlags().BoolVar(&noColor, "no-color", noColor, "Disable colored output. Colors are also disabled if stdout is not a terminal or NO_COLOR is set")
 
-	// hide provider and config. It is for internal use
-	RootCmd.PersistentFlags().MarkHidden("provider")
+	// hide config. It is for internal use
 	RootCmd.PersistentFlags().MarkHidden("config")
+
+	cobra.OnInitialize(initColor)
+}
+
+func initColor() {
+	if noColor {
+		printer.Colors = false
+	}
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -84,6 +107,16 @@ func initConfig() {
 
 func initTool() {
 	viper.Set("project", project)
+	if provider == "" {
+		provider = viper.GetString(reco.ProviderKey)
+	}
+	if provider != "" && provider != reco.ProviderPlatform {
+		client, err := reco.NewProviderClient(provider)
+		if err != nil {
+			exitWithError(err)
+		}
+		tool = client
+	}
 	if err := tool.Init(); err != nil {
 		exitWithError(err)
 	}
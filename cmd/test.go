@@ -1,16 +1,47 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/ReconfigureIO/cobra"
 	"github.com/ReconfigureIO/reco"
 	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
 )
 
 var (
+	testVars = struct {
+		vendor bool
+		wait   string
+		output string
+		junit  string
+	}{
+		wait: "true",
+	}
+
+	sweepVars = struct {
+		params      repeatedFlag
+		metric      string
+		metricName  string
+		concurrency int
+		output      string
+		logs        bool
+	}{
+		metricName: "metric",
+	}
+
+	suiteVars struct {
+		file        string
+		concurrency int
+		junit       string
+	}
+
 	testCmdStart = &cobra.Command{
 		Use:     "run [flags] command -- [args]",
 		Aliases: []string{"r", "start", "starts", "create"},
@@ -29,7 +60,7 @@ var (
 		Long:    fmt.Sprintf("Stream logs for a simulation previously started with 'reco sim run'."),
 		PreRun:  testLogPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Test().Log(args[0], os.Stdout); err != nil {
+			if err := tool.Test().Log(resolveID(tool.Test(), args[0]), os.Stdout); err != nil {
 				exitWithError(err)
 			}
 		},
@@ -39,8 +70,51 @@ var (
 		Use:     fmt.Sprintf("report [simulation_ID]"),
 		Aliases: []string{"reports"},
 		Short:   fmt.Sprintf("View reports for a completed simulation"),
-		Long:    fmt.Sprintf("View reports for a completed simulation, containing area and resource utilisation figures."),
-		Run:     openTestReport,
+		Long: `View reports for a finished simulation, containing its result and area and resource utilisation figures.
+Simulations pass if they complete and their report contains no errors. Errored,
+terminated and timed out simulations fail.`,
+		Run: openTestReport,
+	}
+
+	testCmdSuite = &cobra.Command{
+		Use:   "suite",
+		Short: "Run suites of simulations",
+		Long: fmt.Sprintf(`Run suites of simulations declared in a %s file in the source directory:
+
+  concurrency: 2
+  simulations:
+    - name: addition
+      command: test-addition
+      args: ["-n", "64"]
+    - name: overflow
+      command: test-overflow
+      expect: fail
+
+Simulations pass unless they are expected to fail.`, reco.SimulationSuiteFile),
+	}
+
+	testCmdSuiteRun = &cobra.Command{
+		Use:     "run",
+		Aliases: []string{"r", "start"},
+		Short:   "Run a suite of simulations",
+		Long: `Run a suite of simulations. The source is archived once, uploaded for each simulation,
+and the simulations run in parallel, with their logs prefixed by simulation name. A summary is
+printed once all simulations finish, and the command fails if any simulation did not have its
+expected outcome.`,
+		Run: runTestSuite,
+	}
+
+	testCmdSweep = &cobra.Command{
+		Use:   "sweep [flags] command -- [args]",
+		Short: "Run a simulation with every combination of parameter values",
+		Long: `Run a simulation with every combination of parameter values, e.g.
+
+  reco sim sweep test-kernel --param size=64,128,256 --param mode=a,b
+
+runs 6 simulations. Parameters referenced in args as {name} are substituted,
+others are passed as -name=value. A metric can be extracted from each simulation log
+with a regular expression, e.g. --metric 'cycles: (\d+)'.`,
+		Run: runTestSweep,
 	}
 
 	testLogPreRun = func(cmd *cobra.Command, args []string) {
@@ -50,33 +124,58 @@ var (
 	}
 
 	testCmdStop = &cobra.Command{
-		Use:     "stop [simulation_ID]",
+		Use:     "stop [simulation_ID...]",
 		Aliases: []string{"s", "stp", "stops"},
 		Short:   fmt.Sprintf("Stop a simulation"),
-		Long:    fmt.Sprintf("Stop a simulation previously started with 'reco sim run'"),
-		PreRun:  testStopPreRun,
+		Long: fmt.Sprintf(`Stop simulations previously started with 'reco sim run'.
+Several simulations can be stopped at once by ID, or selected with --all, --status
+and --older-than e.g. 'reco sim stop --all --status started'. The simulations to
+stop are listed for confirmation, unless --yes is given.`),
+		PreRun: stopPreRun,
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := tool.Test().Stop(args[0]); err != nil {
+			if isBulkStop(args) {
+				bulkStop("simulation", tool.Test(), args)
+				return
+			}
+			if err := tool.Test().Stop(resolveID(tool.Test(), args[0])); err != nil {
 				exitWithError(err)
 			}
 			logger.Std.Printf("Simulation stopped successfully")
 		},
 	}
-
-	testStopPreRun = func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			exitWithError("ID required")
-		}
-	}
 )
 
 func init() {
+	testCmdStart.PersistentFlags().StringVarP(&testVars.wait, "wait", "w", testVars.wait, "Wait for the simulation to complete. If false, it only starts the simulation and prints its ID. If status, it waits for the simulation to finish without streaming logs and fails if the simulation does not complete")
+	testCmdStart.PersistentFlags().BoolVar(&testVars.vendor, "vendor", testVars.vendor, "Include dependencies resolved from your local Go module cache or GOPATH in the uploaded source. Missing dependencies are reported before upload")
+
+	addStopFlags(testCmdStop, "simulation")
+
+	testCmdReport.Flags().StringVarP(&testVars.output, "output", "o", testVars.output, "Output format: table, json, yaml, csv or tsv")
+	testCmdReport.Flags().StringVar(&testVars.junit, "junit", testVars.junit, "Also write the result as a JUnit XML file e.g. out.xml")
+
+	testCmdSuiteRun.Flags().StringVarP(&suiteVars.file, "file", "f", suiteVars.file, "Suite file (default \""+reco.SimulationSuiteFile+"\" in the source directory)")
+	testCmdSuiteRun.Flags().IntVarP(&suiteVars.concurrency, "concurrency", "c", suiteVars.concurrency, "Maximum number of simulations to run at once. Overrides the suite's concurrency")
+	testCmdSuiteRun.Flags().StringVar(&suiteVars.junit, "junit", suiteVars.junit, "Also write the results as a JUnit XML file e.g. out.xml")
+	testCmdSuiteRun.Flags().BoolVar(&testVars.vendor, "vendor", testVars.vendor, "Include dependencies resolved from your local Go module cache or GOPATH in the uploaded source. Missing dependencies are reported before upload")
+	testCmdSuite.AddCommand(testCmdSuiteRun)
+
+	testCmdSweep.Flags().Var(&sweepVars.params, "param", "Parameter values of the form name=value1,value2. Can be repeated")
+	testCmdSweep.Flags().StringVar(&sweepVars.metric, "metric", sweepVars.metric, "Regular expression extracting a metric from each simulation log. The first subexpression is used, if any")
+	testCmdSweep.Flags().StringVar(&sweepVars.metricName, "metric-name", sweepVars.metricName, "Name of the metric column")
+	testCmdSweep.Flags().IntVarP(&sweepVars.concurrency, "concurrency", "c", sweepVars.concurrency, "Maximum number of simulations to run at once (default 4)")
+	testCmdSweep.Flags().StringVarP(&sweepVars.output, "output", "o", sweepVars.output, "Output format: table, json, yaml, csv or tsv")
+	testCmdSweep.Flags().BoolVar(&sweepVars.logs, "logs", sweepVars.logs, "Stream simulation logs to stderr, prefixed by parameter values")
+	testCmdSweep.Flags().BoolVar(&testVars.vendor, "vendor", testVars.vendor, "Include dependencies resolved from your local Go module cache or GOPATH in the uploaded source. Missing dependencies are reported before upload")
+
 	testCmd := genDevCommand("sim", "simulation", "simulation", "simulations", "test", "tests", "t")
-	testCmd.AddCommand(genListSubcommand("simulations", tool.Test()))
+	testCmd.AddCommand(genListSubcommand("simulations", func() lister { return tool.Test() }))
 	testCmd.AddCommand(testCmdLog)
 	testCmd.AddCommand(testCmdStop)
 	testCmd.AddCommand(testCmdStart)
 	testCmd.AddCommand(testCmdReport)
+	testCmd.AddCommand(testCmdSuite)
+	testCmd.AddCommand(testCmdSweep)
 	testCmd.PersistentFlags().StringVar(&project, "project", project, "Project to use. If unset, the active project is used")
 
 	RootCmd.AddCommand(testCmd)
@@ -89,20 +188,27 @@ func startTest(cmd *cobra.Command, args []string) {
 	if len(args) < 1 {
 		exitWithUsage(cmd, "command is required")
 	}
-	command := args[0]
-	commandArgs := []string{}
-	if dash := cmd.ArgsLenAtDash(); dash > 0 {
-		commandArgs = args[dash:]
-	} else if len(args) > 1 {
-		commandArgs = args[1:]
+	wait, err := reco.ParseWaitMode(testVars.wait, reco.WaitLog, reco.WaitNone, reco.WaitStatus)
+	if err != nil {
+		exitWithUsage(cmd, err)
 	}
-	id, err := tool.Test().Start(reco.Args{srcDir, command, commandArgs})
+	command, commandArgs := commandLine(cmd, args)
+	id, err := tool.Test().StartSimulation(reco.SimulationOptions{
+		SourceDir: srcDir,
+		Command:   command,
+		Args:      commandArgs,
+		Vendor:    testVars.vendor,
+		Wait:      wait,
+	})
 	if err != nil {
 		exitWithError(err)
 	}
 
 	status := tool.Test().Status(id)
-	logger.Std.Println("Simulation ID: " + id + " Status: " + strings.Title(status))
+	logger.Std.Println("Simulation ID: " + id + " Status: " + strings.Title(strings.ToLower(string(status))))
+	if wait == reco.WaitStatus && !status.Is(reco.StatusCompleted) {
+		exitWithError("simulation did not complete")
+	}
 }
 
 func openTestReport(_ *cobra.Command, args []string) {
@@ -110,10 +216,175 @@ func openTestReport(_ *cobra.Command, args []string) {
 		exitWithError("ID required")
 	}
 
-	report, err := tool.Test().(reco.SimulationReporter).Report(args[0]) // TODO campgareth: Anything but this weird form.
+	format, err := printer.ParseFormat(testVars.output)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	reporter, ok := tool.Test().(reco.SimulationReporter)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	report, err := reporter.Report(resolveID(tool.Test(), args[0]))
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if testVars.junit != "" {
+		if err := writeJUnit(testVars.junit, []reco.SimulationReport{report}); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	switch format {
+	case printer.FormatJSON:
+		b, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			exitWithError(err)
+		}
+		logger.Std.Println(string(b))
+	case printer.FormatTable:
+		if err := printer.Fprint(os.Stdout, report.Table()); err != nil {
+			exitWithError(err)
+		}
+		if !report.Passed() {
+			logger.Std.Println(report.Failure)
+		}
+		if report.Area != nil {
+			printBuildReport(*report.Area)
+		}
+	default:
+		if err := printer.FprintFormat(os.Stdout, report.Table(), format); err != nil {
+			exitWithError(err)
+		}
+	}
+}
+
+// writeJUnit writes simulation reports to a JUnit XML file.
+func writeJUnit(file string, reports []reco.SimulationReport) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := reco.WriteJUnit(f, "reco simulations", reports); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runTestSuite(_ *cobra.Command, _ []string) {
+	if !validBuildDir(srcDir) {
+		exitWithError(errInvalidSourceDirectory)
+	}
+	file := suiteVars.file
+	if file == "" {
+		file = filepath.Join(srcDir, reco.SimulationSuiteFile)
+	}
+	suite, err := reco.LoadSimulationSuite(file)
 	if err != nil {
 		exitWithError(err)
 	}
+	if suiteVars.concurrency < 0 {
+		exitWithError("--concurrency must be at least 1")
+	}
+	if suiteVars.concurrency > 0 {
+		suite.Concurrency = suiteVars.concurrency
+	}
 
-	logger.Std.Println(report)
+	runner, ok := tool.Test().(reco.SimulationSuiteRunner)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	results, err := runner.RunSuite(srcDir, suite, testVars.vendor, os.Stderr)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if suiteVars.junit != "" {
+		reports := make([]reco.SimulationReport, len(results))
+		for i, result := range results {
+			reports[i] = result.Checked()
+		}
+		if err := writeJUnit(suiteVars.junit, reports); err != nil {
+			exitWithError(err)
+		}
+	}
+
+	if err := printer.Fprint(os.Stdout, reco.SuiteTable(results)); err != nil {
+		exitWithError(err)
+	}
+	failed := 0
+	for _, result := range results {
+		if !result.Passed() {
+			failed++
+			logger.Std.Printf("%s: %s", result.Simulation.Name, result.Checked().Failure)
+		}
+	}
+	if failed > 0 {
+		exitWithError(fmt.Errorf("%d of %d simulations failed", failed, len(results)))
+	}
+	logger.Std.Printf("All %d simulations passed", len(results))
+}
+
+// commandLine returns the simulation command and its args.
+func commandLine(cmd *cobra.Command, args []string) (string, []string) {
+	commandArgs := []string{}
+	if dash := cmd.ArgsLenAtDash(); dash > 0 {
+		commandArgs = args[dash:]
+	} else if len(args) > 1 {
+		commandArgs = args[1:]
+	}
+	return args[0], commandArgs
+}
+
+func runTestSweep(cmd *cobra.Command, args []string) {
+	if !validBuildDir(srcDir) {
+		exitWithError(errInvalidSourceDirectory)
+	}
+	if len(args) < 1 {
+		exitWithUsage(cmd, "command is required")
+	}
+	if len(sweepVars.params) == 0 {
+		exitWithUsage(cmd, "at least one --param is required")
+	}
+	format, err := printer.ParseFormat(sweepVars.output)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	sweep := reco.Sweep{Concurrency: sweepVars.concurrency}
+	sweep.Command, sweep.Args = commandLine(cmd, args)
+	for _, p := range sweepVars.params {
+		param, err := reco.ParseSweepParam(p)
+		if err != nil {
+			exitWithError(err)
+		}
+		sweep.Params = append(sweep.Params, param)
+	}
+	if err := sweep.CheckColumns(sweepVars.metricName); err != nil {
+		exitWithError(err)
+	}
+	if sweepVars.metric != "" {
+		if sweep.Metric, err = regexp.Compile(sweepVars.metric); err != nil {
+			exitWithError(fmt.Errorf("invalid metric: %v", err))
+		}
+	}
+
+	var logs io.Writer
+	if sweepVars.logs {
+		logs = os.Stderr
+	}
+	logger.Info.Printf("running %d simulations", len(sweep.Combinations()))
+	sweeper, ok := tool.Test().(reco.SimulationSweeper)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	results, err := sweeper.Sweep(srcDir, sweep, testVars.vendor, logs)
+	if err != nil {
+		exitWithError(err)
+	}
+	if err := printer.FprintFormat(os.Stdout, reco.SweepTable(sweep, results, sweepVars.metricName), format); err != nil {
+		exitWithError(err)
+	}
 }
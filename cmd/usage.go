@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ReconfigureIO/cobra"
+	"github.com/ReconfigureIO/reco"
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var usageVars = struct {
+	since  string
+	by     string
+	output string
+}{
+	by: "project",
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report instance usage and estimated cost",
+	Long: `Report the instance-hours of builds, simulations and deployments across all
+your projects, grouped by project, job type or day. Jobs still running are
+counted until now.
+
+Cost is estimated from per-hour rates of each job type in your reco config
+file (see 'reco config'):
+
+  rates:
+    build: 0.5
+    simulation: 0.25
+    deployment: 1.65
+`,
+	PersistentPreRun: initializeCmd,
+	Run:              usage,
+}
+
+func init() {
+	usageCmd.Flags().StringVar(&usageVars.since, "since", usageVars.since, "Only count jobs started on or after this date e.g. 2026-09-01 (default the start of this month)")
+	usageCmd.Flags().StringVar(&usageVars.by, "by", usageVars.by, "Group usage by "+strings.Join(reco.UsageGroups, ", "))
+	usageCmd.Flags().StringVarP(&usageVars.output, "output", "o", usageVars.output, "Output format: table, json, yaml, csv or tsv")
+	RootCmd.AddCommand(usageCmd)
+}
+
+func usage(_ *cobra.Command, _ []string) {
+	format, err := printer.ParseFormat(usageVars.output)
+	if err != nil {
+		exitWithError(err)
+	}
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if usageVars.since != "" {
+		if since, err = time.ParseInLocation("2006-01-02", usageVars.since, time.Local); err != nil {
+			exitWithError("invalid --since date '" + usageVars.since + "'. Expected YYYY-MM-DD")
+		}
+	}
+
+	reporter, ok := tool.(reco.UsageReporter)
+	if !ok {
+		exitWithError(errUnsupportedProvider)
+	}
+	report, err := reporter.Usage(since, usageVars.by)
+	if err != nil {
+		exitWithError(err)
+	}
+
+	table := report.Table()
+	switch format {
+	case printer.FormatJSON:
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			exitWithError(err)
+		}
+		logger.Std.Println(string(b))
+		return
+	case printer.FormatYAML:
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			exitWithError(err)
+		}
+		if _, err := os.Stdout.Write(b); err != nil {
+			exitWithError(err)
+		}
+		return
+	case printer.FormatTable:
+		logger.Std.Printf("Usage since %s by %s", since.Format("2006-01-02"), report.By)
+	default:
+		// delimited formats mark the total row.
+		var names []string
+		for _, col := range table.Header {
+			names = append(names, col.Name)
+		}
+		if table, err = table.Select(names); err != nil {
+			exitWithError(err)
+		}
+	}
+	if err := printer.FprintFormat(os.Stdout, table, format); err != nil {
+		exitWithError(err)
+	}
+}
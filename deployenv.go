@@ -0,0 +1,181 @@
+package reco
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/reconfigureio/archiver"
+)
+
+const (
+	// maxDeploymentInput is the largest total size of deployment input files.
+	maxDeploymentInput = 10 << 20
+	// secretMask replaces secret values in output.
+	secretMask = "****"
+)
+
+// secretEnvWords are words of environment variable names with secret values.
+var secretEnvWords = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// ParseEnv parses a KEY=VALUE environment variable into env.
+func ParseEnv(env map[string]string, s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("invalid environment variable '%s'. Expected KEY=VALUE", s)
+	}
+	env[s[:i]] = s[i+1:]
+	return nil
+}
+
+// LoadEnvFile loads environment variables from a file of KEY=VALUE
+// lines into env. Blank lines, comments starting with # and export
+// prefixes are ignored, and quotes around values are removed.
+func LoadEnvFile(env map[string]string, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		if err := ParseEnv(env, line); err != nil {
+			return fmt.Errorf("%s:%d: %v", file, n, err)
+		}
+		key := line[:strings.Index(line, "=")]
+		if value := env[key]; len(value) >= 2 && strings.ContainsRune(`"'`, rune(value[0])) && value[len(value)-1] == value[0] {
+			env[key] = value[1 : len(value)-1]
+		}
+	}
+	return scanner.Err()
+}
+
+// isSecretEnv checks if an environment variable has a secret value,
+// by its name.
+func isSecretEnv(name string) bool {
+	name = strings.ToUpper(name)
+	for _, word := range secretEnvWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// envString returns env as sorted KEY=VALUE pairs, with secret
+// values masked.
+func envString(env map[string]string) string {
+	var keys []string
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		value := env[key]
+		if isSecretEnv(key) {
+			value = secretMask
+		}
+		pairs[i] = key + "=" + value
+	}
+	return strings.Join(pairs, " ")
+}
+
+// envSecrets returns the secret values of env.
+func envSecrets(env map[string]string) []string {
+	var secrets []string
+	for key, value := range env {
+		if value != "" && isSecretEnv(key) {
+			secrets = append(secrets, value)
+		}
+	}
+	// mask longer secrets first, in case secrets contain each other.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// maskWriter masks secrets in lines written to w.
+type maskWriter struct {
+	w       io.Writer
+	secrets []string
+	buf     []byte
+}
+
+// newMaskWriter returns a writer masking secrets in the lines written to
+// w. It must be closed to write a final incomplete line.
+func newMaskWriter(w io.Writer, secrets []string) io.WriteCloser {
+	return &maskWriter{w: w, secrets: secrets}
+}
+
+func (m *maskWriter) Write(b []byte) (int, error) {
+	m.buf = append(m.buf, b...)
+	i := bytes.LastIndexByte(m.buf, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+	lines := m.buf[:i+1]
+	if _, err := io.WriteString(m.w, m.mask(string(lines))); err != nil {
+		return 0, err
+	}
+	m.buf = append(m.buf[:0], m.buf[i+1:]...)
+	return len(b), nil
+}
+
+func (m *maskWriter) Close() error {
+	if len(m.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(m.w, m.mask(string(m.buf)))
+	m.buf = nil
+	return err
+}
+
+func (m *maskWriter) mask(s string) string {
+	for _, secret := range m.secrets {
+		s = strings.Replace(s, secret, secretMask, -1)
+	}
+	return s
+}
+
+// archiveInputs archives deployment input files, which must be
+// regular files of at most maxDeploymentInput bytes in total.
+func archiveInputs(files []string) (string, error) {
+	var total int64
+	names := make(map[string]string)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		if !info.Mode().IsRegular() {
+			return "", fmt.Errorf("input '%s' is not a file", file)
+		}
+		name := filepath.Base(file)
+		if other, ok := names[name]; ok {
+			return "", fmt.Errorf("inputs '%s' and '%s' have the same name", other, file)
+		}
+		names[name] = file
+		total += info.Size()
+	}
+	if total > maxDeploymentInput {
+		return "", errors.New("input files are too large. Deployment inputs are limited to 10MB in total")
+	}
+	tmp, err := tmpDir()
+	if err != nil {
+		return "", err
+	}
+	tmpArchive := path.Join(tmp, "input.tar.gz")
+	return tmpArchive, archiver.TarGz.Make(tmpArchive, files)
+}
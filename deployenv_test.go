@@ -0,0 +1,133 @@
+package reco
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, ".env")
+	content := "# settings\nMODE=fast\n\nexport NAME=\"reco test\"\nAPI_TOKEN='abc=123'\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := make(map[string]string)
+	if err := LoadEnvFile(env, file); err != nil {
+		t.Fatal(err)
+	}
+	if err := ParseEnv(env, "MODE=slow"); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"MODE": "slow", "NAME": "reco test", "API_TOKEN": "abc=123"}
+	if len(env) != len(expected) {
+		t.Errorf("expected %v, got %v", expected, env)
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, env[key])
+		}
+	}
+	if s := envString(env); s != "API_TOKEN=**** MODE=slow NAME=reco test" {
+		t.Errorf("unexpected env string %q", s)
+	}
+
+	if err := ParseEnv(env, "=value"); err == nil {
+		t.Error("expected error for missing key")
+	}
+	if err := ioutil.WriteFile(file, []byte("MODE\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadEnvFile(env, file); err == nil {
+		t.Error("expected error for invalid line")
+	}
+}
+
+func TestMaskWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newMaskWriter(&buf, envSecrets(map[string]string{"DB_PASSWORD": "hunter2", "MODE": "fast"}))
+	for _, s := range []string{"connecting with hun", "ter2 in fast mode\n", "done hunter2"} {
+		w.Write([]byte(s))
+	}
+	w.Close()
+	if s := buf.String(); s != "connecting with **** in fast mode\ndone ****" {
+		t.Errorf("unexpected output %q", s)
+	}
+}
+
+func TestArchiveInputsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "data.bin")
+	b := filepath.Join(dir, "b", "data.bin")
+	os.Mkdir(filepath.Dir(b), 0755)
+	ioutil.WriteFile(a, []byte("a"), 0644)
+	ioutil.WriteFile(b, []byte("b"), 0644)
+
+	if _, err := archiveInputs([]string{a, b}); err == nil {
+		t.Error("expected error for inputs with the same name")
+	}
+	if _, err := archiveInputs([]string{dir}); err == nil {
+		t.Error("expected error for directory input")
+	}
+	big := filepath.Join(dir, "big.bin")
+	if err := ioutil.WriteFile(big, make([]byte, maxDeploymentInput+1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archiveInputs([]string{big}); err == nil {
+		t.Error("expected error for large inputs")
+	}
+}
+
+func TestInputUploadFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "data.bin")
+	if err := ioutil.WriteFile(input, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stopped bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/deployments":
+			json.NewEncoder(w).Encode(M{"value": M{"id": "dep1"}})
+		case r.Method == "PUT" && r.URL.Path == "/deployments/dep1/input":
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Method == "POST" && r.URL.Path == "/deployments/dep1/events":
+			stopped = true
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	job := deploymentJob{&clientImpl{platformServer: server.URL, Username: "user", Token: "token"}}
+	id, err := job.StartDeployment(DeploymentOptions{Build: "build1", Command: "run", Inputs: []string{input}, Wait: WaitNone})
+	if err == nil {
+		t.Error("failed upload did not fail")
+	}
+	if id != "dep1" {
+		t.Errorf("Expected deployment ID dep1, found %q", id)
+	}
+	if !stopped {
+		t.Error("deployment without its inputs was not stopped")
+	}
+}
@@ -4,38 +4,56 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/ReconfigureIO/reco/logger"
 	"github.com/ReconfigureIO/reco/printer"
-	humanize "github.com/dustin/go-humanize"
 	"github.com/skratchdot/open-golang/open"
 )
 
 // DeploymentProxy proxies to a running deployment instance.
 type DeploymentProxy interface {
-	// Connect performs a proxy connection.
-	Connect(id string, openBrowser bool) error
+	// Connect waits for the deployment to pass a readiness probe.
+	Connect(id string, probe Probe, openBrowser bool) error
 }
 
-var _ Job = deploymentJob{}
+var _ DeploymentJob = deploymentJob{}
 var _ DeploymentProxy = deploymentJob{}
 
 type deploymentJob struct {
 	*clientImpl
 }
 
+// Start starts a deployment with positional args.
+//
+// Deprecated: use StartDeployment.
 func (p deploymentJob) Start(args Args) (string, error) {
-	buildID := String(args.At(0))
-	command := String(args.At(1))
-	wait := String(args.Last())
-	cmdArgs := StringSlice(args.At(2))
+	return p.StartDeployment(deploymentOptions(args))
+}
+
+func (p deploymentJob) StartDeployment(opts DeploymentOptions) (string, error) {
+	opts.Probe = opts.probe()
+	if err := opts.Probe.Validate(); opts.Wait == WaitHTTP && err != nil {
+		return "", err
+	}
+	var inputArchive string
+	if len(opts.Inputs) > 0 {
+		var err error
+		if inputArchive, err = archiveInputs(opts.Inputs); err != nil {
+			return "", err
+		}
+	}
+	buildID, err := p.resolveBuild(opts.Build)
+	if err != nil {
+		return "", err
+	}
 
 	req := p.apiRequest(endpoints.deployments.String())
-	if len(args) > 0 {
-		command += " " + strings.Join(cmdArgs, " ")
+	command := opts.Command
+	if len(opts.Args) > 0 {
+		command += " " + strings.Join(opts.Args, " ")
 	}
 
 	logger.Info.Println("creating deployment")
@@ -43,6 +61,14 @@ func (p deploymentJob) Start(args Args) (string, error) {
 		"build_id": buildID,
 		"command":  command,
 	}
+	if len(opts.Env) > 0 {
+		logger.Info.Println("environment: ", envString(opts.Env))
+		reqBody["env"] = opts.Env
+	}
+	if opts.TTL > 0 {
+		// platforms supporting it stop the deployment themselves.
+		reqBody["ttl"] = int(opts.TTL.Seconds())
+	}
 	resp, err := req.Do("POST", reqBody)
 	if err != nil {
 		return "", err
@@ -63,21 +89,45 @@ func (p deploymentJob) Start(args Args) (string, error) {
 		return "", errUnknownError
 	}
 
+	if opts.TTL > 0 {
+		deadline := time.Now().Add(opts.TTL)
+		if err := recordDeadline(respJSON.Value.ID, deadline); err != nil {
+			return respJSON.Value.ID, fmt.Errorf("could not record the deadline of deployment %s, so it will not be reaped: %v", respJSON.Value.ID, err)
+		}
+		logger.Info.Printf("deployment will be stopped by 'reco deploy reap' after %s", deadline.Format(time.RFC3339))
+	}
+
+	if inputArchive != "" {
+		logger.Info.Println("uploading input files")
+		if err := p.uploadJob(JobTypeDeployment, respJSON.Value.ID, inputArchive); err != nil {
+			// the command must not run without its inputs.
+			if stopErr := p.stopJob(JobTypeDeployment, respJSON.Value.ID); stopErr != nil {
+				return respJSON.Value.ID, fmt.Errorf("uploading input files failed: %v. Stopping the deployment also failed: %v", err, stopErr)
+			}
+			return respJSON.Value.ID, fmt.Errorf("uploading input files failed, so the deployment was stopped: %v", err)
+		}
+	}
+
 	logger.Info.Println("done. Deployment ID: ", respJSON.Value.ID)
 	logger.Info.Println(`you can run "reco deployment log `, respJSON.Value.ID, `" to manually stream logs`)
-	if wait == "true" {
-		return respJSON.Value.ID, p.waitAndLog("deployment", respJSON.Value.ID)
-	} else if wait == "http" {
+	switch opts.Wait {
+	case WaitNone:
+	case WaitHTTP:
 		err := p.waitForStatus("deployment", respJSON.Value.ID, StatusStarted)
 		if err == nil {
-			err = p.Connect(respJSON.Value.ID, false)
+			err = p.Connect(respJSON.Value.ID, opts.Probe, false)
 		}
 		return respJSON.Value.ID, err
+	default:
+		if err := p.waitForStatus("deployment", respJSON.Value.ID, StatusStarted); err != nil {
+			return respJSON.Value.ID, err
+		}
+		return respJSON.Value.ID, p.maskedLog(respJSON.Value.ID, opts.Env)
 	}
-	return "", nil
+	return respJSON.Value.ID, nil
 }
 
-func (p deploymentJob) Status(id string) string {
+func (p deploymentJob) Status(id string) Status {
 	return p.clientImpl.getStatus("deployment", id)
 }
 
@@ -94,74 +144,89 @@ func (p deploymentJob) Stop(id string) error {
 }
 
 func (p deploymentJob) List(filter M) (printer.Table, error) {
-	var table printer.Table
 	allProjects := filter.Bool("all")
 	deployments, err := p.clientImpl.listDeployments(filter)
 	if err != nil {
-		return table, err
+		return printer.Table{}, err
 	}
+	return jobTable(deployments, allProjects,
+		colID.titled("deployment id"),
+		colBuild,
+		colCommand,
+		colStatus,
+		colStarted,
+		colDuration,
+		colEnv.hidden(),
+	), nil
+}
 
-	var body [][]string
-	for _, deployment := range deployments {
-		buildTime := "-"
-		if !deployment.Time.IsZero() {
-			buildTime = humanize.Time(deployment.Time)
-		}
-
-		row := []string{
-			deployment.ID,
-			deployment.Build,
-			deployment.Command,
-			deployment.Status,
-			buildTime,
-			timeRounder(deployment.Duration).Nearest(time.Second),
-		}
-		if allProjects {
-			row = append(row, deployment.Project)
-		}
-
-		body = append(body, row)
+// Log streams the log of a deployment, masking the secret values of
+// its environment.
+func (p deploymentJob) Log(id string, writer io.Writer) error {
+	job, err := p.getJob(JobTypeDeployment, id)
+	if err != nil {
+		return err
 	}
+	return p.maskedLog(id, job.Env)
+}
 
-	table = printer.Table{
-		Header: []string{"deployment id", "build id", "command", "status", "started", "duration"},
-		Body:   body,
+func (p deploymentJob) maskedLog(id string, env map[string]string) error {
+	secrets := envSecrets(env)
+	if len(secrets) == 0 {
+		return p.clientImpl.logJob("deployment", id)
 	}
-	if allProjects {
-		table.Header = append(table.Header, "project")
+	w := newMaskWriter(os.Stderr, secrets)
+	err := p.streamLog(JobTypeDeployment, id, w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
 	}
-
-	return table, nil
+	return err
 }
 
-func (p deploymentJob) Log(id string, writer io.Writer) error {
-	return p.clientImpl.logJob("deployment", id)
-}
-
-func (p deploymentJob) Connect(id string, openBrowser bool) error {
-	logger.Info.Println("Waiting for deployment to listen on port 80")
+// Connect waits until the deployment passes probe, polling every probe
+// interval, and prints the result and latency of each attempt.
+func (p deploymentJob) Connect(id string, probe Probe, openBrowser bool) error {
+	if err := probe.Validate(); err != nil {
+		return err
+	}
+	logger.Info.Printf("Waiting for deployment to pass %s", probe)
+	// the timeout starts once the deployment has started, as time
+	// queued for an instance is not up to the deployment.
+	var deadline time.Time
+	attempts := 0
 	for {
 		resp, err := p.clientImpl.getJob("deployment", id)
 		if err != nil {
 			return err
 		}
+		if deadline.IsZero() && probe.Timeout > 0 && resp.IsStarted() {
+			deadline = time.Now().Add(probe.Timeout)
+		}
 
 		if resp.IsCompleted() {
 			return errors.New("instance has shutdown")
 		}
 
 		if resp.IPAddress != "" {
-			conn, err := net.DialTimeout("tcp", resp.IPAddress+":80", 5*time.Second)
-			if conn != nil && err == nil {
-				_ = conn.Close()
-				logger.Info.Printf("Deployment ready at http://%s/", resp.IPAddress)
+			if attempts == 0 {
+				time.Sleep(probe.Delay)
+			}
+			attempts++
+			latency, err := probe.Check(resp.IPAddress)
+			if err == nil {
+				logger.Info.Printf("Probe passed in %s after %d attempt(s)", latency, attempts)
+				logger.Info.Printf("Deployment ready at %s", probe.URL(resp.IPAddress))
 				if openBrowser {
-					return open.Run(fmt.Sprintf("http://%s/", resp.IPAddress))
+					return open.Run(probe.URL(resp.IPAddress))
 				}
 				return nil
 			}
+			logger.Info.Printf("Probe failed in %s: %v", latency, err)
 		}
 
-		time.Sleep(10 * time.Second)
+		if !deadline.IsZero() && time.Now().Add(probe.Interval).After(deadline) {
+			return fmt.Errorf("deployment not ready after %s", probe.Timeout)
+		}
+		time.Sleep(probe.Interval)
 	}
 }
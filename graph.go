@@ -7,7 +7,6 @@ import (
 	"github.com/ReconfigureIO/reco/downloader"
 	"github.com/ReconfigureIO/reco/logger"
 	"github.com/ReconfigureIO/reco/printer"
-	humanize "github.com/dustin/go-humanize"
 )
 
 // Graph manages reco graphs.
@@ -50,6 +49,7 @@ func (b platformGraph) prepareGraph() (string, error) {
 func (p platformGraph) Generate(args Args) (string, error) {
 	srcDir := String(args.At(0))
 	wait := Bool(args.At(1))
+	vendor := Bool(args.At(2))
 
 	logger.Info.Println("preparing graph")
 	id, err := p.prepareGraph()
@@ -59,7 +59,7 @@ func (p platformGraph) Generate(args Args) (string, error) {
 	logger.Info.Println("done. Graph ID: ", id)
 
 	logger.Info.Println("archiving")
-	srcArchive, err := archiveDir(srcDir)
+	srcArchive, err := archiveDir(srcDir, vendor)
 	if err != nil {
 		return "", err
 	}
@@ -79,40 +79,16 @@ func (p platformGraph) Generate(args Args) (string, error) {
 }
 
 func (p platformGraph) List(filter M) (printer.Table, error) {
-	var table printer.Table
 	allProjects := filter.Bool("all")
 	graphs, err := p.clientImpl.listGraphs(filter)
 	if err != nil {
-		return table, err
+		return printer.Table{}, err
 	}
-	var body [][]string
-	for _, graph := range graphs {
-		buildTime := "-"
-		if !graph.Time.IsZero() {
-			buildTime = humanize.Time(graph.Time)
-		}
-
-		row := []string{
-			graph.ID,
-			graph.Status,
-			buildTime,
-		}
-		if allProjects {
-			row = append(row, graph.Project)
-		}
-
-		body = append(body, row)
-	}
-
-	table = printer.Table{
-		Header: []string{"graph id", "status", "requested"},
-		Body:   body,
-	}
-	if allProjects {
-		table.Header = append(table.Header, "project")
-	}
-
-	return table, nil
+	return jobTable(graphs, allProjects,
+		colID.titled("graph id"),
+		colStatus,
+		colStarted.titled("requested"),
+	), nil
 }
 
 func (p platformGraph) Open(id string) (string, error) {
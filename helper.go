@@ -7,7 +7,6 @@ import (
 	"path"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/reconfigureio/archiver"
 )
@@ -88,6 +87,9 @@ func StringSlice(v interface{}) []string {
 
 // Args is convenience wrapper for []interface
 // to fetch element at without wrong index errors.
+//
+// Jobs are started with typed options, e.g. BuildOptions. Args remain
+// for the deprecated Job.Start and graph generation.
 type Args []interface{}
 
 // At returns element at i.
@@ -114,7 +116,10 @@ func (a Args) First() interface{} {
 	return nil
 }
 
-func archiveDir(dir string) (string, error) {
+// archiveDir archives dir for upload. If vendor is set, the project's
+// dependencies are resolved locally and included in the archive in place
+// of any existing vendor directory.
+func archiveDir(dir string, vendor bool) (string, error) {
 	tmp, err := tmpDir()
 	if err != nil {
 		return "", err
@@ -125,11 +130,24 @@ func archiveDir(dir string) (string, error) {
 		".reco-work",
 		".reco",
 	}
+	if vendor {
+		ignoredFiles = append(ignoredFiles, "vendor")
+	}
 	files := ignoreFiles(dir, ignoredFiles)
 	if len(files) == 0 {
 		return "", fmt.Errorf("'%s' is empty", dir)
 	}
 
+	if vendor {
+		vendorDir, err := vendorDeps(dir)
+		if err != nil {
+			return "", err
+		}
+		if vendorDir != "" {
+			files = append(files, vendorDir)
+		}
+	}
+
 	return tmpArchive, archiver.TarGz.Make(tmpArchive, files)
 }
 
@@ -166,20 +184,3 @@ func ignoreFiles(src string, ignored []string) (fs []string) {
 	}
 	return
 }
-
-type timeRounder time.Duration
-
-// Nearest rounds to the nearest duration and returns the string.
-func (t timeRounder) Nearest(d time.Duration) string {
-	duration := time.Duration(t)
-	str := "-"
-	if t > 0 {
-		// round to duration e.g. sec, min
-		// easy trick with integer division
-		duration /= d
-		duration *= d
-
-		str = duration.String()
-	}
-	return str
-}
@@ -2,6 +2,7 @@ package reco
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"sort"
 	"strings"
@@ -12,52 +13,53 @@ import (
 
 // Job is a set of actions for the reco platform.
 type Job interface {
-	// Start starts the job.
+	// Start starts the job with positional args.
+	//
+	// Deprecated: use the typed start method of the job type.
 	Start(Args) (output string, err error)
 	// Stop stops the job.
 	Stop(id string) error
-	// Status returns the status of the job.
-	Status(id string) string
+	// Status returns the status of the job, in upper case.
+	Status(id string) Status
 	// List lists job resources.
 	List(filter M) (printer.Table, error)
 	// Log logs the job.
 	Log(id string, writer io.Writer) error
 }
 
-var (
-	// StatusSubmitted is submitted job state.
-	StatusSubmitted = "SUBMITTED"
-	// StatusQueued is queued job state.
-	StatusQueued = "QUEUED"
-	// StatusCreatingImage is creating image job state.
-	StatusCreatingImage = "CREATING_IMAGE"
-	// StatusStarted is started job state.
-	StatusStarted = "STARTED"
-	// StatusTerminating is terminating job state.
-	StatusTerminating = "TERMINATING"
-	// StatusTerminated is terminated job state.
-	StatusTerminated = "TERMINATED"
-	// StatusCompleted is completed job state.
-	StatusCompleted = "COMPLETED"
-	// StatusErrored is errored job state.
-	StatusErrored = "ERRORED"
-	// An error event with code value 124 indicates timeout
-	StatusTimeout = "TIMED-OUT"
-	// An error event with Code value 124 indicates timeout
-	ErrorCodeTimeout = 124
-)
+// BuildJob is a set of actions for builds.
+type BuildJob interface {
+	Job
+	BuildStarter
+}
+
+// SimulationJob is a set of actions for simulations.
+type SimulationJob interface {
+	Job
+	SimulationStarter
+}
+
+// DeploymentJob is a set of actions for deployments.
+type DeploymentJob interface {
+	Job
+	DeploymentStarter
+}
 
 // jobInfo gives information about a build.
 type jobInfo struct {
 	ID        string
 	Time      time.Time
 	Duration  time.Duration
-	Status    string
+	Status    Status
 	Project   string
 	Command   string
 	Build     string
 	IPAddress string
 	Message   string
+	// Reason is the message of the event that ended the job, if any.
+	Reason string
+	// Env is the environment of deployments.
+	Env map[string]string
 }
 
 // UnmarshalJSON customizes JSON decoding for BuildInfo.
@@ -79,6 +81,7 @@ func (ji *jobInfo) UnmarshalJSON(b []byte) error {
 	ji.Command = str.Command
 	ji.IPAddress = str.IPAddress
 	ji.Message = str.Message
+	ji.Env = str.Env
 	if str.Build.ID != "" {
 		ji.Build = str.Build.ID
 	}
@@ -89,20 +92,24 @@ func (ji *jobInfo) UnmarshalJSON(b []byte) error {
 		// Handle terminated status with a prior final status.
 		if len(str.Job.Events) > 2 {
 			ev := str.Job.Events[len(str.Job.Events)-2]
-			if isCompleted(ev.Status) {
+			if ev.Status.IsFinal() {
 				lastEvent = ev
 			}
 		}
+		ji.Reason = lastEvent.Message
 		for _, event := range str.Job.Events {
 			if isTimeout(event) {
 				// Timeout isn't a Status reported by API
 				// but we know the error event was caused by a timeout
 				// so report that to user
 				lastEvent.Status = StatusTimeout
+				if event.Message != "" {
+					ji.Reason = event.Message
+				}
 			}
 		}
 
-		ji.Status = strings.ToLower(lastEvent.Status)
+		ji.Status = lastEvent.Status.upper()
 		ji.Time = firstEvent.Timestamp
 		if eventSorter(str.Job.Events).Completed() {
 			ji.Duration = lastEvent.Timestamp.Sub(firstEvent.Timestamp)
@@ -112,11 +119,105 @@ func (ji *jobInfo) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-type jobSorter []jobInfo
+// jobColumn is a column of a job listing.
+type jobColumn struct {
+	printer.Column
+	value func(jobInfo) interface{}
+}
+
+// titled returns the column with a different title.
+func (c jobColumn) titled(title string) jobColumn {
+	c.Title = title
+	return c
+}
+
+// hidden returns the column hidden unless selected.
+func (c jobColumn) hidden() jobColumn {
+	c.Hidden = true
+	return c
+}
+
+var (
+	colID = jobColumn{printer.Column{Name: "id", Title: "id"},
+		func(job jobInfo) interface{} { return job.ID }}
+	colBuild = jobColumn{printer.Column{Name: "build_id", Title: "build id"},
+		func(job jobInfo) interface{} { return job.Build }}
+	colCommand = jobColumn{printer.Column{Name: "command", Title: "command"},
+		func(job jobInfo) interface{} { return job.Command }}
+	colStatus = jobColumn{printer.Column{Name: "status", Title: "status"},
+		func(job jobInfo) interface{} {
+			return printer.Colored{Value: job.Status.lower(), Color: statusColor(job.Status)}
+		}}
+	colStarted = jobColumn{printer.Column{Name: "started", Title: "started"},
+		func(job jobInfo) interface{} { return job.Time }}
+	colDuration = jobColumn{printer.Column{Name: "duration", Title: "duration"},
+		func(job jobInfo) interface{} { return job.Duration }}
+	colMessage = jobColumn{printer.Column{Name: "message", Title: "message"},
+		func(job jobInfo) interface{} { return job.Message }}
+	colEnv = jobColumn{printer.Column{Name: "env", Title: "environment"},
+		func(job jobInfo) interface{} { return envString(job.Env) }}
+	colProject = jobColumn{printer.Column{Name: "project", Title: "project"},
+		func(job jobInfo) interface{} { return job.Project }}
+)
+
+// jobTable returns a table of jobs with columns. The project
+// column is hidden unless listing all projects.
+func jobTable(jobs []jobInfo, allProjects bool, columns ...jobColumn) printer.Table {
+	if allProjects {
+		columns = append(columns, colProject)
+	} else {
+		columns = append(columns, colProject.hidden())
+	}
+
+	var table printer.Table
+	for _, col := range columns {
+		table.Header = append(table.Header, col.Column)
+	}
+	for _, job := range jobs {
+		row := make(printer.Row, len(columns))
+		for i, col := range columns {
+			row[i] = col.value(job)
+		}
+		table.Body = append(table.Body, row)
+	}
+	return table
+}
+
+// jobOrders are the orderings jobs can be sorted by.
+var jobOrders = map[string]func(a, b jobInfo) bool{
+	// newest first
+	"started": func(a, b jobInfo) bool { return a.Time.After(b.Time) },
+	// longest first
+	"duration": func(a, b jobInfo) bool { return a.Duration > b.Duration },
+	// alphabetical
+	"status": func(a, b jobInfo) bool { return a.Status < b.Status },
+}
+
+type jobSorter struct {
+	jobs []jobInfo
+	less func(a, b jobInfo) bool
+}
+
+func (b jobSorter) Len() int           { return len(b.jobs) }
+func (b jobSorter) Less(i, j int) bool { return b.less(b.jobs[i], b.jobs[j]) }
+func (b jobSorter) Swap(i, j int)      { b.jobs[i], b.jobs[j] = b.jobs[j], b.jobs[i] }
 
-func (b jobSorter) Len() int           { return len(b) }
-func (b jobSorter) Less(i, j int) bool { return b[i].Time.After(b[j].Time) }
-func (b jobSorter) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+// sortJobs sorts jobs by key, newest first if key is empty.
+func sortJobs(jobs []jobInfo, key string, reverse bool) error {
+	if key == "" {
+		key = "started"
+	}
+	less, ok := jobOrders[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("cannot sort by '%s'. Jobs can be sorted by started, duration or status", key)
+	}
+	if reverse {
+		forward := less
+		less = func(a, b jobInfo) bool { return forward(b, a) }
+	}
+	sort.Stable(jobSorter{jobs: jobs, less: less})
+	return nil
+}
 
 type jobFilter []jobInfo
 
@@ -134,7 +235,7 @@ func (b jobFilter) Filter(key, val string) (bi []jobInfo) {
 	switch key {
 	case "status":
 		return b.doFilter(func(info *jobInfo) bool {
-			return strings.ToUpper(info.Status) == strings.ToUpper(val)
+			return info.Status.Is(Status(val))
 		})
 	case "id":
 		return b.doFilter(func(info *jobInfo) bool {
@@ -153,33 +254,15 @@ func (e eventSorter) Completed() bool {
 	if len(e) < 2 {
 		return false
 	}
-	return isCompleted(e[len(e)-1].Status)
-}
-
-// isCompleted checks if the status is a final status.
-func isCompleted(status string) bool {
-	switch strings.ToUpper(status) {
-	case StatusCompleted, StatusErrored, StatusTerminated:
-		return true
-	}
-	return false
+	return e[len(e)-1].Status.IsFinal()
 }
 
 func (job jobInfo) IsCompleted() bool {
-	return isCompleted(job.Status)
+	return job.Status.IsFinal()
 }
 
 func (job jobInfo) IsStarted() bool {
-	return isStarted(job.Status)
-}
-
-// isCompleted checks if the status is a final status.
-func isStarted(status string) bool {
-	switch strings.ToUpper(status) {
-	case StatusCompleted, StatusErrored, StatusTerminated, StatusTerminating, StatusStarted, StatusCreatingImage:
-		return true
-	}
-	return false
+	return job.Status.IsStarted()
 }
 
 func isTimeout(ev event) bool {
@@ -192,9 +275,5 @@ func isTimeout(ev event) bool {
 }
 
 func isError(ev event) bool {
-	switch strings.ToUpper(ev.Status) {
-	case StatusErrored:
-		return true
-	}
-	return false
+	return ev.Status.Is(StatusErrored)
 }
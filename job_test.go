@@ -2,6 +2,7 @@ package reco
 
 import (
 	"testing"
+	"time"
 )
 
 var (
@@ -40,6 +41,8 @@ var (
 		jobInfo{Status: "CREATING_IMAGE"},
 		jobInfo{Status: "STARTED"},
 		jobInfo{Status: "TERMINATING"},
+		jobInfo{Status: "timed-out"},
+		jobInfo{Status: StatusTimeout},
 	}
 
 	jobsNotStarted = []jobInfo{
@@ -75,3 +78,38 @@ func TestIsStarted(t *testing.T) {
 		}
 	}
 }
+
+func TestSortJobs(t *testing.T) {
+	now := time.Now()
+	jobs := []jobInfo{
+		{ID: "a", Status: "completed", Time: now.Add(-time.Hour), Duration: time.Minute},
+		{ID: "b", Status: "errored", Time: now, Duration: time.Hour},
+		{ID: "c", Status: "queued", Time: now.Add(-2 * time.Hour)},
+	}
+	orders := []struct {
+		key      string
+		reverse  bool
+		expected string
+	}{
+		{"", false, "bac"},
+		{"started", true, "cab"},
+		{"duration", false, "bac"},
+		{"status", false, "abc"},
+		{"status", true, "cba"},
+	}
+	for _, order := range orders {
+		if err := sortJobs(jobs, order.key, order.reverse); err != nil {
+			t.Error(err)
+		}
+		ids := ""
+		for _, job := range jobs {
+			ids += job.ID
+		}
+		if ids != order.expected {
+			t.Errorf("sort by %q reverse=%v: expected %s, got %s", order.key, order.reverse, order.expected, ids)
+		}
+	}
+	if err := sortJobs(jobs, "unknown", false); err == nil {
+		t.Error("sortJobs should fail for unknown keys")
+	}
+}
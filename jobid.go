@@ -0,0 +1,149 @@
+package reco
+
+import (
+	"fmt"
+	"strings"
+)
+
+// idLength is the length of complete job IDs, which are UUIDs.
+const idLength = 36
+
+// IDResolver can resolve unique prefixes of job IDs.
+type IDResolver interface {
+	// ResolveID returns the ID of the job of the project starting with
+	// prefix. Complete IDs are returned as is.
+	ResolveID(prefix string) (string, error)
+}
+
+// JobFinder can find jobs of any type by ID.
+type JobFinder interface {
+	// FindJob returns the type and complete ID of the job with ID,
+	// or ID prefix, id.
+	FindJob(id string) (jobType string, fullID string, err error)
+}
+
+// matchID returns the ID in ids starting with prefix. It returns
+// ErrNotFound if there is none.
+func matchID(ids []string, prefix string) (string, error) {
+	var matches []string
+	for _, id := range ids {
+		if strings.HasPrefix(id, prefix) {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return "", ambiguousID(prefix, matches)
+}
+
+func ambiguousID(prefix string, matches []string) error {
+	return fmt.Errorf("ID prefix '%s' is ambiguous. It matches %s", prefix, strings.Join(matches, ", "))
+}
+
+func jobIDs(jobs []jobInfo) []string {
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return ids
+}
+
+// resolveID returns the ID of the job of jobType in the project
+// starting with prefix.
+func (p *clientImpl) resolveID(jobType, prefix string) (string, error) {
+	if len(prefix) >= idLength {
+		return prefix, nil
+	}
+	jobs, err := p.listJobs(jobType, M{})
+	if err != nil {
+		return "", err
+	}
+	return matchID(jobIDs(jobs), prefix)
+}
+
+func (b buildJob) ResolveID(prefix string) (string, error) {
+	return b.resolveID(JobTypeBuild, prefix)
+}
+
+func (t testJob) ResolveID(prefix string) (string, error) {
+	return t.resolveID(JobTypeSimulation, prefix)
+}
+
+func (p deploymentJob) ResolveID(prefix string) (string, error) {
+	return p.resolveID(JobTypeDeployment, prefix)
+}
+
+func (p platformGraph) ResolveID(prefix string) (string, error) {
+	return p.resolveID(JobTypeGraph, prefix)
+}
+
+// FindJob finds a job by querying the endpoint of each job type.
+// Prefixes are matched against the jobs of the project.
+func (p *clientImpl) FindJob(id string) (string, string, error) {
+	type match struct{ jobType, id string }
+	var matches []match
+	for _, kind := range jobKinds {
+		jobType := kind.name
+		if len(id) >= idLength {
+			_, err := p.getJob(jobType, id)
+			if err == ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return "", "", err
+			}
+			return jobType, id, nil
+		}
+		jobs, err := p.listJobs(jobType, M{})
+		if err != nil {
+			return "", "", err
+		}
+		for _, jobID := range jobIDs(jobs) {
+			if strings.HasPrefix(jobID, id) {
+				matches = append(matches, match{jobType, jobID})
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", "", ErrNotFound
+	case 1:
+		return matches[0].jobType, matches[0].id, nil
+	}
+	var ids []string
+	for _, m := range matches {
+		ids = append(ids, m.jobType+" "+m.id)
+	}
+	return "", "", ambiguousID(id, ids)
+}
+
+func (l localSimulation) ResolveID(prefix string) (string, error) {
+	if len(prefix) >= idLength {
+		return prefix, nil
+	}
+	jobs, err := l.store.all()
+	if err != nil {
+		return "", err
+	}
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+	}
+	return matchID(ids, prefix)
+}
+
+// FindJob finds a local job. Only simulations run locally.
+func (l *localClient) FindJob(id string) (string, string, error) {
+	id, err := localSimulation{l}.ResolveID(id)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := l.store.load(id); err != nil {
+		return "", "", err
+	}
+	return JobTypeSimulation, id, nil
+}
@@ -0,0 +1,52 @@
+package reco
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMatchID(t *testing.T) {
+	ids := []string{
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		"6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+		"f47ac10b-58cc-4372-a567-0e02b2c3d479",
+	}
+	if id, err := matchID(ids, "f4"); err != nil || id != ids[2] {
+		t.Errorf("expected %s, got %s %v", ids[2], id, err)
+	}
+	if id, err := matchID(ids, "6ba7b811"); err != nil || id != ids[1] {
+		t.Errorf("expected %s, got %s %v", ids[1], id, err)
+	}
+	if _, err := matchID(ids, "00"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	_, err := matchID(ids, "6ba7")
+	if err == nil || !strings.Contains(err.Error(), ids[0]) || !strings.Contains(err.Error(), ids[1]) {
+		t.Errorf("expected ambiguous prefix error listing matches, got %v", err)
+	}
+}
+
+func TestLocalFindJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client := &localClient{store: localStore{dir: dir}}
+	id := "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	if err := client.store.save(localJob{ID: id, Name: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	jobType, fullID, err := client.FindJob("f47")
+	if err != nil || jobType != JobTypeSimulation || fullID != id {
+		t.Errorf("expected simulation %s, got %s %s %v", id, jobType, fullID, err)
+	}
+	if _, _, err := client.FindJob("00"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, _, err := client.FindJob("00000000-0000-0000-0000-000000000000"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
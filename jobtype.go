@@ -0,0 +1,129 @@
+package reco
+
+import "fmt"
+
+// jobOp is an operation on jobs of the platform.
+type jobOp int
+
+const (
+	opGet jobOp = iota
+	opList
+	opUpload
+	opLog
+	opStop
+	opReport
+)
+
+// jobKind describes a job type of the platform.
+type jobKind struct {
+	name     string
+	endpoint Endpoint
+	// upload, report, logs and stop are the operations supported
+	// beyond getting and listing jobs.
+	upload, report, logs, stop bool
+	// waiting are the messages shown while a job waits in a status.
+	waiting map[Status]string
+}
+
+// jobKinds are the job types of the platform. New job types are
+// added here.
+var jobKinds = []jobKind{
+	{
+		name:     JobTypeBuild,
+		endpoint: endpoints.builds,
+		upload:   true,
+		report:   true,
+		logs:     true,
+		stop:     true,
+		waiting: map[Status]string{
+			StatusQueued: "Waiting for job to start",
+		},
+	},
+	{
+		name:     JobTypeSimulation,
+		endpoint: endpoints.simulations,
+		upload:   true,
+		report:   true,
+		logs:     true,
+		stop:     true,
+		waiting: map[Status]string{
+			StatusQueued: "Waiting for job to start",
+		},
+	},
+	{
+		name:     JobTypeDeployment,
+		endpoint: endpoints.deployments,
+		upload:   true,
+		logs:     true,
+		stop:     true,
+		waiting: map[Status]string{
+			StatusSubmitted: "Waiting for request to be queued",
+			StatusQueued:    "Waiting for FPGA instance to be available",
+		},
+	},
+	{
+		name:     JobTypeGraph,
+		endpoint: endpoints.graphs,
+		upload:   true,
+	},
+}
+
+// lookupJobKind returns the job kind of jobType.
+func lookupJobKind(jobType string) (jobKind, error) {
+	for _, kind := range jobKinds {
+		if kind.name == jobType {
+			return kind, nil
+		}
+	}
+	return jobKind{}, fmt.Errorf("unknown job type '%s'", jobType)
+}
+
+// supports checks if jobs of the kind support op.
+func (k jobKind) supports(op jobOp) bool {
+	switch op {
+	case opUpload:
+		return k.upload
+	case opLog:
+		return k.logs
+	case opStop:
+		return k.stop
+	case opReport:
+		return k.report
+	}
+	return true
+}
+
+// endpointOf returns the endpoint of op.
+func (k jobKind) endpointOf(op jobOp) string {
+	switch op {
+	case opGet:
+		return k.endpoint.Item()
+	case opUpload:
+		return k.endpoint.Input()
+	case opLog:
+		return k.endpoint.Log()
+	case opStop:
+		return k.endpoint.Events()
+	case opReport:
+		return k.endpoint.Report()
+	}
+	return k.endpoint.String()
+}
+
+// jobRequest returns a request for op on jobs of jobType. The id
+// param is set unless id is empty. It returns errUnsupported if the
+// job type does not support op.
+func (p *clientImpl) jobRequest(jobType string, op jobOp, id string) (clientRequest, error) {
+	kind, err := lookupJobKind(jobType)
+	if err != nil {
+		return clientRequest{}, err
+	}
+	if !kind.supports(op) {
+		return clientRequest{}, errUnsupported
+	}
+	req := p.apiRequest(kind.endpointOf(op))
+	if id != "" {
+		req.param("id", id)
+	}
+	return req, nil
+}
@@ -0,0 +1,53 @@
+package reco
+
+import "testing"
+
+func TestLookupJobKind(t *testing.T) {
+	for _, kind := range jobKinds {
+		if k, err := lookupJobKind(kind.name); err != nil || k.name != kind.name {
+			t.Errorf("lookupJobKind(%s) = %s, %v", kind.name, k.name, err)
+		}
+	}
+	if _, err := lookupJobKind("unknown"); err == nil {
+		t.Error("unknown job type did not fail")
+	}
+}
+
+func TestJobRequest(t *testing.T) {
+	p := &clientImpl{platformServer: "http://server", Username: "user", Token: "token"}
+	req, err := p.jobRequest(JobTypeDeployment, opLog, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "http://server/deployments/{id}/logs"; req.endpoint != expected || req.params["id"] != "id" {
+		t.Errorf("Expected %s with id, found %s %v", expected, req.endpoint, req.params)
+	}
+	req, err = p.jobRequest(JobTypeSimulation, opList, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "http://server/simulations"; req.endpoint != expected || len(req.params) != 0 {
+		t.Errorf("Expected %s, found %s %v", expected, req.endpoint, req.params)
+	}
+
+	unsupported := []struct {
+		jobType string
+		op      jobOp
+	}{
+		{JobTypeGraph, opStop},
+		{JobTypeGraph, opLog},
+		{JobTypeGraph, opReport},
+		{JobTypeDeployment, opReport},
+	}
+	for _, u := range unsupported {
+		if _, err := p.jobRequest(u.jobType, u.op, "id"); err != errUnsupported {
+			t.Errorf("%s op %d: expected errUnsupported, found %v", u.jobType, u.op, err)
+		}
+	}
+	if err := p.stopJob(JobTypeGraph, "id"); err != errUnsupported {
+		t.Errorf("Expected errUnsupported stopping a graph, found %v", err)
+	}
+	if _, err := p.getJob("unknown", "id"); err == nil {
+		t.Error("getJob of unknown job type did not fail")
+	}
+}
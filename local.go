@@ -0,0 +1,494 @@
+package reco
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+// LocalJobEnv is the environment variable set when reco is run to
+// supervise a local job. Its value is the job file.
+const LocalJobEnv = "RECO_LOCAL_JOB"
+
+const (
+	// localJobsDir is the local job store, in the local config directory.
+	localJobsDir = "local"
+	// localPollInterval is the interval to wait before checking local
+	// job updates.
+	localPollInterval = 500 * time.Millisecond
+	// localTestCommand is the simulation command running 'go test'.
+	localTestCommand = "test"
+)
+
+// NewLocalClient creates a client that runs simulations as local
+// processes, without network access. Other actions are unsupported.
+func NewLocalClient() Client {
+	return &localClient{}
+}
+
+var _ Client = &localClient{}
+
+type localClient struct {
+	store localStore
+}
+
+func (l *localClient) Init() error {
+	l.store = localStore{dir: filepath.Join(viper.GetString(ConfigDirKey), localJobsDir)}
+	return os.MkdirAll(l.store.dir, 0755)
+}
+
+func (l *localClient) Auth(token string) error {
+	return errUnsupported
+}
+
+func (l *localClient) Test() SimulationJob {
+	return localSimulation{l}
+}
+
+func (l *localClient) Build() BuildJob {
+	return unsupportedJob{}
+}
+
+func (l *localClient) Deployment() DeploymentJob {
+	return unsupportedJob{}
+}
+
+func (l *localClient) Project() ProjectConfig {
+	return unsupportedProject{}
+}
+
+func (l *localClient) Graph() Graph {
+	return unsupportedGraph{}
+}
+
+// localJob is a job run as a local process.
+type localJob struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Args     []string  `json:"args,omitempty"`
+	Dir      string    `json:"dir"`
+	Status   Status    `json:"status"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	PID      int       `json:"pid,omitempty"`
+	// Supervisor is the PID of the reco process running the job.
+	Supervisor int    `json:"supervisor,omitempty"`
+	ExitCode   int    `json:"exit_code"`
+	Message    string `json:"message,omitempty"`
+}
+
+func (j localJob) command() string {
+	return strings.Join(append([]string{j.Name}, j.Args...), " ")
+}
+
+func (j localJob) info() jobInfo {
+	info := jobInfo{
+		ID:      j.ID,
+		Time:    j.Started,
+		Status:  j.Status.upper(),
+		Command: j.command(),
+		Reason:  j.Message,
+	}
+	if !j.Finished.IsZero() {
+		info.Duration = j.Finished.Sub(j.Started)
+	}
+	return info
+}
+
+// localStore stores local jobs as files in dir: the job as <id>.json,
+// its log as <id>.log and a stop request as <id>.stop.
+type localStore struct {
+	dir string
+}
+
+func (s localStore) file(id, ext string) string {
+	return filepath.Join(s.dir, id+ext)
+}
+
+func (s localStore) load(id string) (localJob, error) {
+	var job localJob
+	b, err := ioutil.ReadFile(s.file(id, ".json"))
+	if os.IsNotExist(err) {
+		return job, ErrNotFound
+	}
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal(b, &job)
+	return job, err
+}
+
+// save writes the job atomically, so concurrent readers never
+// see a partial job.
+func (s localStore) save(job localJob) error {
+	b, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	tmp := s.file(job.ID, ".json.tmp")
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.file(job.ID, ".json"))
+}
+
+func (s localStore) all() ([]localJob, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var jobs []localJob
+	for _, file := range files {
+		job, err := s.load(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// checkSupervisor marks an unfinished job whose supervisor is gone as
+// errored, as it will never be given a final status.
+func (s localStore) checkSupervisor(job localJob) (localJob, error) {
+	if job.Status.IsFinal() || job.Supervisor == 0 || processAlive(job.Supervisor) {
+		return job, nil
+	}
+	// the supervisor may have saved a final status since job was loaded.
+	latest, err := s.load(job.ID)
+	if err != nil || latest.Status.IsFinal() {
+		return latest, err
+	}
+	latest.Status = StatusErrored
+	latest.Message = "simulation supervisor exited"
+	latest.Finished = time.Now()
+	return latest, s.save(latest)
+}
+
+func (s localStore) stopRequested(id string) bool {
+	_, err := os.Stat(s.file(id, ".stop"))
+	return err == nil
+}
+
+// newLocalID returns a random UUID for a local job.
+func newLocalID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+var _ SimulationJob = localSimulation{}
+var _ SimulationReporter = localSimulation{}
+var _ BulkStopper = localSimulation{}
+
+// localSimulation runs simulation commands as local processes. Commands
+// in the cmd directory of the source are built and run, and the test
+// command runs 'go test'.
+type localSimulation struct {
+	*localClient
+}
+
+// Start starts a simulation with positional args.
+//
+// Deprecated: use StartSimulation.
+func (l localSimulation) Start(args Args) (string, error) {
+	return l.StartSimulation(simulationOptions(args))
+}
+
+func (l localSimulation) StartSimulation(opts SimulationOptions) (string, error) {
+	name := opts.Command
+	dir, err := filepath.Abs(opts.SourceDir)
+	if err != nil {
+		return "", err
+	}
+	if name != localTestCommand {
+		if _, err := os.Stat(filepath.Join(dir, "cmd", name)); err != nil {
+			return "", fmt.Errorf("unknown command '%s'. Commands are '%s' or the name of a directory in cmd", name, localTestCommand)
+		}
+	}
+	id, err := newLocalID()
+	if err != nil {
+		return "", err
+	}
+	job := localJob{
+		ID:      id,
+		Name:    name,
+		Args:    opts.Args,
+		Dir:     dir,
+		Status:  StatusSubmitted,
+		Started: time.Now(),
+	}
+	if err := l.store.save(job); err != nil {
+		return "", err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	supervisor := exec.Command(exe)
+	supervisor.Env = append(os.Environ(), LocalJobEnv+"="+l.store.file(id, ".json"))
+	detach(supervisor)
+	// the supervisor waits for its stdin to close, so its PID is
+	// recorded before it saves the job.
+	ready, err := supervisor.StdinPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := supervisor.Start(); err != nil {
+		return "", err
+	}
+	// recorded here too, as the supervisor may exit before saving it.
+	job.Supervisor = supervisor.Process.Pid
+	err = l.store.save(job)
+	ready.Close()
+	// reaped, so the supervisor exiting is noticed while waiting.
+	go supervisor.Wait()
+	if err != nil {
+		return id, err
+	}
+	logger.Info.Println("done. Simulation ID: ", id)
+
+	switch opts.Wait {
+	case WaitNone:
+		logger.Info.Println(`you can run "reco sim log `, id, `" to manually stream logs`)
+	case WaitStatus:
+		logger.Info.Println("waiting for simulation to finish")
+		_, err = l.waitForJob(id)
+	default:
+		logger.Info.Println("running simulation")
+		logger.Info.Println()
+		err = l.Log(id, os.Stderr)
+	}
+	return id, err
+}
+
+// waitForJob waits for a job to finish.
+func (l localSimulation) waitForJob(id string) (localJob, error) {
+	for {
+		job, err := l.store.load(id)
+		if err == nil {
+			job, err = l.store.checkSupervisor(job)
+		}
+		if err != nil || job.Status.IsFinal() {
+			return job, err
+		}
+		time.Sleep(localPollInterval)
+	}
+}
+
+func (l localSimulation) Stop(id string) error {
+	job, err := l.store.load(id)
+	if err != nil {
+		return err
+	}
+	if job.Status.IsFinal() {
+		return nil
+	}
+	if err := ioutil.WriteFile(l.store.file(id, ".stop"), nil, 0644); err != nil {
+		return err
+	}
+	if job.PID == 0 {
+		// the supervisor checks for the stop request before starting.
+		return nil
+	}
+	return killProcessGroup(job.PID)
+}
+
+func (l localSimulation) Status(id string) Status {
+	job, err := l.store.load(id)
+	if err != nil {
+		return StatusErrored
+	}
+	return job.Status.upper()
+}
+
+func (l localSimulation) List(filter M) (printer.Table, error) {
+	jobs, err := l.store.all()
+	if err != nil {
+		return printer.Table{}, err
+	}
+	var simulations []jobInfo
+	for _, job := range jobs {
+		simulations = append(simulations, job.info())
+	}
+	status, err := statusFilter(filter)
+	if err != nil {
+		return printer.Table{}, err
+	}
+	if status != "" {
+		simulations = jobFilter(simulations).Filter("status", string(status))
+	}
+	if err := sortJobs(simulations, filter.String("sort"), filter.Bool("reverse")); err != nil {
+		return printer.Table{}, err
+	}
+	if limit := filter.Int("limit"); limit > 0 && limit < len(simulations) {
+		simulations = simulations[:limit]
+	}
+	return jobTable(simulations, false,
+		colID.titled("simulation id"),
+		colStatus,
+		colStarted,
+		colDuration,
+		colCommand,
+	), nil
+}
+
+// Log writes the log of a simulation to writer, following it until
+// the simulation finishes.
+func (l localSimulation) Log(id string, writer io.Writer) error {
+	var log *os.File
+	defer func() {
+		if log != nil {
+			log.Close()
+		}
+	}()
+	for {
+		job, err := l.store.load(id)
+		if err == nil {
+			job, err = l.store.checkSupervisor(job)
+		}
+		if err != nil {
+			return err
+		}
+		if log == nil {
+			if log, err = os.Open(l.store.file(id, ".log")); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if log != nil {
+			if _, err := io.Copy(writer, log); err != nil {
+				return err
+			}
+		}
+		// the job is loaded before copying, so the log is complete.
+		if job.Status.IsFinal() {
+			return nil
+		}
+		time.Sleep(localPollInterval)
+	}
+}
+
+func (l localSimulation) Report(id string) (SimulationReport, error) {
+	job, err := l.store.load(id)
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	if !job.Status.IsFinal() {
+		return SimulationReport{}, fmt.Errorf("Simulation has not finished. Status: %s", job.Status.lower())
+	}
+	return newSimulationReport(job.info(), ""), nil
+}
+
+// RunLocalJob runs the local job in file, writing its log and status
+// to the job store. It is run by a reco process started for the job,
+// so the job outlives the reco invocation starting it.
+func RunLocalJob(file string) error {
+	// wait for the starting reco process to record the supervisor.
+	io.Copy(ioutil.Discard, os.Stdin)
+	store := localStore{dir: filepath.Dir(file)}
+	job, err := store.load(strings.TrimSuffix(filepath.Base(file), ".json"))
+	if err != nil {
+		return err
+	}
+	job.Supervisor = os.Getpid()
+	if err := store.save(job); err != nil {
+		return err
+	}
+	log, err := os.Create(store.file(job.ID, ".log"))
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	err = runLocalJob(store, &job, log)
+	job.Finished = time.Now()
+	switch {
+	case store.stopRequested(job.ID):
+		job.Status = StatusTerminated
+		job.Message = "stopped"
+	case err == nil:
+		job.Status = StatusCompleted
+	default:
+		job.Status = StatusErrored
+		job.Message = err.Error()
+		job.ExitCode = exitCode(err)
+		fmt.Fprintln(log, err)
+	}
+	os.Remove(store.file(job.ID, ".stop"))
+	return store.save(job)
+}
+
+func runLocalJob(store localStore, job *localJob, log io.Writer) error {
+	var cmd *exec.Cmd
+	if job.Name == localTestCommand {
+		args := job.Args
+		if len(args) == 0 {
+			args = []string{"./..."}
+		}
+		cmd = exec.Command("go", append([]string{"test"}, args...)...)
+	} else {
+		// build before running, so stopping kills the command itself.
+		bin := store.file(job.ID, ".bin")
+		if runtime.GOOS == "windows" {
+			bin += ".exe"
+		}
+		defer os.Remove(bin)
+		build := exec.Command("go", "build", "-o", bin, "./cmd/"+job.Name)
+		build.Dir = job.Dir
+		build.Stdout = log
+		build.Stderr = log
+		if err := build.Run(); err != nil {
+			return errors.New("build failed")
+		}
+		cmd = exec.Command(bin, job.Args...)
+	}
+	cmd.Dir = job.Dir
+	cmd.Stdout = log
+	cmd.Stderr = log
+	// in its own process group, so stopping kills processes it starts,
+	// e.g. the test binaries of go test.
+	detach(cmd)
+
+	if store.stopRequested(job.ID) {
+		return nil
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	job.PID = cmd.Process.Pid
+	job.Status = StatusStarted
+	if err := store.save(*job); err != nil {
+		cmd.Process.Kill()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// exitCode returns the exit code of a failed command, or 1.
+func exitCode(err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus()
+		}
+	}
+	return 1
+}
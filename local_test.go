@@ -0,0 +1,113 @@
+package reco
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sim := localSimulation{&localClient{store: localStore{dir: dir}}}
+
+	if _, err := sim.store.load("missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	started := time.Now().Add(-time.Minute)
+	jobs := []localJob{
+		{ID: "1", Name: "test-a", Args: []string{"-n", "1"}, Status: StatusCompleted, Started: started, Finished: started.Add(30 * time.Second)},
+		{ID: "2", Name: "test", Status: StatusStarted, Started: started.Add(time.Second)},
+		{ID: "3", Name: "test-b", Status: StatusErrored, Started: started.Add(2 * time.Second), Finished: started.Add(time.Minute), Message: "exit status 1"},
+	}
+	for _, job := range jobs {
+		if err := sim.store.save(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if status := sim.Status("2"); status != StatusStarted {
+		t.Errorf("unexpected status %q", status)
+	}
+	table, err := sim.List(M{"status": "completed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Body) != 1 || table.Value(0, "command") != "test-a -n 1" || table.Value(0, "duration") != 30*time.Second {
+		t.Errorf("unexpected list %+v", table.Body)
+	}
+	table, err = sim.List(M{"limit": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Body) != 2 || table.Value(0, "id") != "3" {
+		t.Errorf("expected newest 2 simulations, got %+v", table.Body)
+	}
+
+	report, err := sim.Report("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() || report.Failure != "Simulation errored: exit status 1" {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if _, err := sim.Report("2"); err == nil {
+		t.Error("expected error for unfinished simulation report")
+	}
+	if err := sim.Stop("1"); err != nil || sim.store.stopRequested("1") {
+		t.Errorf("stopping a finished simulation should do nothing, got %v", err)
+	}
+}
+
+func TestNewLocalID(t *testing.T) {
+	a, err := newLocalID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := newLocalID()
+	if len(a) != 36 || a == b {
+		t.Errorf("unexpected IDs %q %q", a, b)
+	}
+}
+
+func TestLocalSupervisorExited(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sim := localSimulation{&localClient{store: localStore{dir: dir}}}
+
+	// a process that has exited, as a supervisor that died.
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	jobs := []localJob{
+		{ID: "running", Status: StatusStarted, Supervisor: os.Getpid()},
+		{ID: "orphaned", Status: StatusStarted, Supervisor: exited.Process.Pid},
+	}
+	for _, job := range jobs {
+		if err := sim.store.save(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	job, err := sim.waitForJob("orphaned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusErrored {
+		t.Errorf("Expected orphaned job to be errored, found %s", job.Status)
+	}
+	if err := sim.Log("orphaned", ioutil.Discard); err != nil {
+		t.Error(err)
+	}
+	if job, _ := sim.store.checkSupervisor(jobs[0]); job.Status != StatusStarted {
+		t.Errorf("Expected running job to be started, found %s", job.Status)
+	}
+}
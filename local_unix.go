@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package reco
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach runs cmd in its own process group, so it is not
+// interrupted with reco.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process group of process pid.
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// processAlive checks if process pid is running.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
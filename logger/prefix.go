@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineLock serializes lines written by prefix writers, so lines
+// of concurrent writers sharing an output are not interleaved.
+var lineLock sync.Mutex
+
+type prefixWriter struct {
+	writer io.Writer
+	prefix string
+	buf    []byte
+}
+
+// NewPrefixWriter returns a writer that writes each line to w
+// starting with prefix. Incomplete lines are buffered until
+// the writer is closed.
+func NewPrefixWriter(w io.Writer, prefix string) io.WriteCloser {
+	return &prefixWriter{writer: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Close writes any incomplete line.
+func (p *prefixWriter) Close() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	// drop keep-alive null bytes of streamed logs.
+	line = bytes.Replace(line, []byte{0}, nil, -1)
+	lineLock.Lock()
+	defer lineLock.Unlock()
+	_, err := p.writer.Write(append([]byte(p.prefix), line...))
+	return err
+}
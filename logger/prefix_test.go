@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewPrefixWriter(&buf, "[sim] ")
+	io.WriteString(w, "first\nsec")
+	io.WriteString(w, "ond\n\x00third")
+	if expected := "[sim] first\n[sim] second\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+	w.Close()
+	if expected := "[sim] first\n[sim] second\n[sim] third\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,184 @@
+package reco
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WaitMode is how starting a job waits for it. The empty mode is
+// WaitLog.
+type WaitMode string
+
+const (
+	// WaitNone returns once the job is submitted.
+	WaitNone WaitMode = "false"
+	// WaitLog streams the log of the job until it finishes.
+	WaitLog WaitMode = "true"
+	// WaitStatus waits for the job to finish without streaming its log.
+	WaitStatus WaitMode = "status"
+	// WaitHTTP waits for a deployment to pass its readiness probe.
+	WaitHTTP WaitMode = "http"
+)
+
+// ParseWaitMode parses a wait mode, one of modes.
+func ParseWaitMode(s string, modes ...WaitMode) (WaitMode, error) {
+	var names []string
+	for _, mode := range modes {
+		if WaitMode(s) == mode {
+			return mode, nil
+		}
+		names = append(names, string(mode))
+	}
+	return "", fmt.Errorf("invalid wait mode '%s'. Modes are %s", s, strings.Join(names, ", "))
+}
+
+// BuildOptions are the options of a build.
+type BuildOptions struct {
+	// SourceDir is the directory of the source to build.
+	SourceDir string
+	// Message describes the build. It defaults to <branch>@<commit>
+	// in a git repository.
+	Message string
+	// Vendor includes dependencies in the uploaded source.
+	Vendor bool
+	// Wait is WaitNone, WaitLog or WaitStatus.
+	Wait WaitMode
+}
+
+// SimulationOptions are the options of a simulation.
+type SimulationOptions struct {
+	// SourceDir is the directory of the source to simulate.
+	SourceDir string
+	// Command is the simulation command, with arguments Args.
+	Command string
+	Args    []string
+	// Vendor includes dependencies in the uploaded source.
+	Vendor bool
+	// Wait is WaitNone, WaitLog or WaitStatus.
+	Wait WaitMode
+}
+
+// DeploymentOptions are the options of a deployment.
+type DeploymentOptions struct {
+	// Build is the ID of the build to deploy, or a build reference.
+	Build string
+	// Command is the command of the build to run, with arguments Args.
+	Command string
+	Args    []string
+	// Wait is WaitNone, WaitLog or WaitHTTP.
+	Wait WaitMode
+	// Probe is the readiness probe of WaitHTTP. The zero probe is
+	// DefaultProbe.
+	Probe Probe
+	// Env is the environment of the command.
+	Env map[string]string
+	// Inputs are small files uploaded with the deployment.
+	Inputs []string
+	// TTL is the time to live of the deployment, or 0.
+	TTL time.Duration
+}
+
+// BuildStarter starts builds.
+type BuildStarter interface {
+	// StartBuild starts a build and returns its ID.
+	StartBuild(BuildOptions) (string, error)
+}
+
+// SimulationStarter starts simulations.
+type SimulationStarter interface {
+	// StartSimulation starts a simulation and returns its ID.
+	StartSimulation(SimulationOptions) (string, error)
+}
+
+// DeploymentStarter starts deployments.
+type DeploymentStarter interface {
+	// StartDeployment starts a deployment and returns its ID.
+	StartDeployment(DeploymentOptions) (string, error)
+}
+
+// The positional Args of Job.Start are:
+//
+//	build:      source dir, wait (bool or mode), message, vendor
+//	simulation: source dir, command, args, vendor, wait
+//	deployment: build, command, args, wait, probe, env, inputs, ttl
+
+func buildOptions(args Args) BuildOptions {
+	wait := WaitNone
+	switch v := args.At(1).(type) {
+	case string, WaitMode:
+		wait = waitMode(v)
+	default:
+		// older callers pass whether to wait with logs.
+		if Bool(v) {
+			wait = WaitLog
+		}
+	}
+	return BuildOptions{
+		SourceDir: String(args.At(0)),
+		Wait:      wait,
+		Message:   String(args.At(2)),
+		Vendor:    Bool(args.At(3)),
+	}
+}
+
+func (o BuildOptions) args() Args {
+	return Args{o.SourceDir, string(o.Wait), o.Message, o.Vendor}
+}
+
+func simulationOptions(args Args) SimulationOptions {
+	return SimulationOptions{
+		SourceDir: String(args.At(0)),
+		Command:   String(args.At(1)),
+		Args:      StringSlice(args.At(2)),
+		Vendor:    Bool(args.At(3)),
+		Wait:      waitMode(args.At(4)),
+	}
+}
+
+func (o SimulationOptions) args() Args {
+	return Args{o.SourceDir, o.Command, o.Args, o.Vendor, string(o.Wait)}
+}
+
+func deploymentOptions(args Args) DeploymentOptions {
+	opts := DeploymentOptions{
+		Build:   String(args.At(0)),
+		Command: String(args.At(1)),
+		Args:    StringSlice(args.At(2)),
+		Wait:    waitMode(args.At(3)),
+		Probe:   DefaultProbe(),
+		Inputs:  StringSlice(args.At(6)),
+	}
+	if probe, ok := args.At(4).(Probe); ok {
+		opts.Probe = probe
+	}
+	opts.Env, _ = args.At(5).(map[string]string)
+	opts.TTL, _ = args.At(7).(time.Duration)
+	return opts
+}
+
+// probe returns the readiness probe of the deployment.
+func (o DeploymentOptions) probe() Probe {
+	if o.Probe == (Probe{}) {
+		return DefaultProbe()
+	}
+	return o.Probe
+}
+
+func (o DeploymentOptions) args() Args {
+	return Args{o.Build, o.Command, o.Args, string(o.Wait), o.Probe, o.Env, o.Inputs, o.TTL}
+}
+
+// waitMode returns the wait mode of a positional argument. Jobs
+// are waited for with logs by default.
+func waitMode(v interface{}) WaitMode {
+	switch v := v.(type) {
+	case WaitMode:
+		return v
+	case string:
+		if v != "" {
+			return WaitMode(v)
+		}
+	}
+	return WaitLog
+}
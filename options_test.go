@@ -0,0 +1,60 @@
+package reco
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWaitMode(t *testing.T) {
+	if mode, err := ParseWaitMode("status", WaitLog, WaitNone, WaitStatus); err != nil || mode != WaitStatus {
+		t.Errorf("expected status, got %q %v", mode, err)
+	}
+	if _, err := ParseWaitMode("http", WaitLog, WaitNone, WaitStatus); err == nil {
+		t.Error("expected error for unsupported mode")
+	}
+}
+
+func TestOptionsArgs(t *testing.T) {
+	for _, wait := range []WaitMode{WaitLog, WaitStatus, WaitNone} {
+		build := BuildOptions{SourceDir: "src", Message: "fix", Vendor: true, Wait: wait}
+		if opts := buildOptions(build.args()); opts != build {
+			t.Errorf("expected %+v, got %+v", build, opts)
+		}
+	}
+	// older callers pass a bool.
+	if opts := buildOptions(Args{"src", true}); opts.Wait != WaitLog {
+		t.Errorf("expected wait with logs, got %q", opts.Wait)
+	}
+	if opts := buildOptions(Args{"src", false}); opts.Wait != WaitNone {
+		t.Errorf("expected no wait, got %q", opts.Wait)
+	}
+
+	sim := SimulationOptions{SourceDir: "src", Command: "test-a", Args: []string{"-n", "1"}, Wait: WaitStatus}
+	if opts := simulationOptions(sim.args()); !reflect.DeepEqual(opts, sim) {
+		t.Errorf("expected %+v, got %+v", sim, opts)
+	}
+
+	deploy := DeploymentOptions{
+		Build:   "latest",
+		Command: "serve",
+		Args:    []string{"-p", "80"},
+		Wait:    WaitHTTP,
+		Probe:   DefaultProbe(),
+		Env:     map[string]string{"MODE": "fast"},
+		Inputs:  []string{"data.bin"},
+		TTL:     30 * time.Minute,
+	}
+	if opts := deploymentOptions(deploy.args()); !reflect.DeepEqual(opts, deploy) {
+		t.Errorf("expected %+v, got %+v", deploy, opts)
+	}
+
+	// positional args default to waiting with logs and the default probe.
+	opts := deploymentOptions(Args{"latest", "serve"})
+	if opts.Wait != WaitLog || opts.Probe != DefaultProbe() {
+		t.Errorf("unexpected defaults %+v", opts)
+	}
+	if probe := (DeploymentOptions{Wait: WaitHTTP}).probe(); probe != DefaultProbe() || probe.Validate() != nil {
+		t.Errorf("expected the default probe for a zero probe, got %+v", probe)
+	}
+}
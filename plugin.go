@@ -0,0 +1,328 @@
+package reco
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+// A provider plugin is an executable serving JSON-RPC 1.0 requests
+// on stdin and writing responses to stdout, as net/rpc/jsonrpc does.
+// Each request has a single parameter object. Methods without a result
+// may return null. The methods are:
+//
+//	Provider.Init   {config_dir, global_config_dir, project}  -> null
+//	Provider.Auth   {token}                                   -> null
+//	Provider.Start  {type, args}                              -> job ID
+//	Provider.Stop   {type, id}                                -> null
+//	Provider.Status {type, id}                                -> status
+//	Provider.List   {type, filter}                            -> {columns: [{name, title}], rows: [[value]]}
+//	Provider.Log    {type, id, offset}                        -> {data, done}
+//
+// type is build, simulation or deployment. args are the command
+// arguments in the order the platform client takes them. Log returns
+// the log from byte offset and is called until done is true. A method
+// returning the error "unsupported" is not supported by the plugin.
+// Plugins may write diagnostics to stderr. reco closes the plugin's
+// stdin when it exits, and plugins should then exit.
+
+// pluginUnsupported is the error message of unsupported plugin methods.
+const pluginUnsupported = "unsupported"
+
+// pluginExitTimeout is how long a plugin has to exit once its stdin
+// is closed, before it is killed.
+const pluginExitTimeout = 5 * time.Second
+
+type pluginInitArgs struct {
+	ConfigDir       string `json:"config_dir"`
+	GlobalConfigDir string `json:"global_config_dir"`
+	Project         string `json:"project"`
+}
+
+type pluginJobArgs struct {
+	Type   string        `json:"type"`
+	ID     string        `json:"id,omitempty"`
+	Args   []interface{} `json:"args,omitempty"`
+	Filter M             `json:"filter,omitempty"`
+	Offset int64         `json:"offset,omitempty"`
+}
+
+type pluginTable struct {
+	Columns []struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	} `json:"columns"`
+	Rows [][]interface{} `json:"rows"`
+}
+
+type pluginLog struct {
+	Data string `json:"data"`
+	Done bool   `json:"done"`
+}
+
+// NewPluginClient creates a client for the provider plugin executable
+// at path. The plugin is started when the client is initiated.
+func NewPluginClient(path string) Client {
+	return &pluginClient{path: path}
+}
+
+var _ Client = &pluginClient{}
+var _ io.Closer = &pluginClient{}
+
+type pluginClient struct {
+	path string
+	rpc  *pluginConn
+	// cmd is the plugin process, if started.
+	cmd *exec.Cmd
+}
+
+// stdio is the standard input and output of a plugin process.
+type stdio struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (s stdio) Close() error {
+	s.WriteCloser.Close()
+	return s.ReadCloser.Close()
+}
+
+// start starts the plugin process.
+func (p *pluginClient) start() error {
+	cmd := exec.Command(p.path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.cmd = cmd
+	p.connect(stdio{stdout, stdin})
+	return nil
+}
+
+// Close closes the stdin of the plugin process and waits for it to
+// exit, killing it if it does not exit in time.
+func (p *pluginClient) Close() error {
+	if p.cmd == nil {
+		return nil
+	}
+	p.rpc.conn.Close()
+	done := make(chan error, 1)
+	go func() { done <- p.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(pluginExitTimeout):
+		p.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+func (p *pluginClient) connect(conn io.ReadWriteCloser) {
+	p.rpc = &pluginConn{conn: conn, dec: json.NewDecoder(conn), enc: json.NewEncoder(conn)}
+}
+
+func (p *pluginClient) call(method string, args interface{}, reply interface{}) error {
+	return p.rpc.call("Provider."+method, args, reply)
+}
+
+// pluginConn is a JSON-RPC 1.0 connection to a plugin. Unlike
+// net/rpc/jsonrpc clients, it accepts null results.
+type pluginConn struct {
+	sync.Mutex
+	conn io.ReadWriteCloser
+	dec  *json.Decoder
+	enc  *json.Encoder
+	seq  uint64
+}
+
+type pluginRequest struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     uint64        `json:"id"`
+}
+
+type pluginResponse struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+func (c *pluginConn) call(method string, args interface{}, reply interface{}) error {
+	c.Lock()
+	defer c.Unlock()
+	c.seq++
+	if err := c.enc.Encode(pluginRequest{Method: method, Params: []interface{}{args}, ID: c.seq}); err != nil {
+		return err
+	}
+	var resp pluginResponse
+	if err := c.dec.Decode(&resp); err != nil {
+		return err
+	}
+	if resp.ID != c.seq {
+		return fmt.Errorf("plugin responded to request %d, expected %d", resp.ID, c.seq)
+	}
+	if resp.Error != nil {
+		msg := fmt.Sprint(resp.Error)
+		if msg == pluginUnsupported {
+			return errUnsupported
+		}
+		return errors.New(msg)
+	}
+	if reply == nil || len(resp.Result) == 0 || string(resp.Result) == "null" {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, reply)
+}
+
+func (p *pluginClient) Init() error {
+	if p.rpc == nil {
+		if err := p.start(); err != nil {
+			return err
+		}
+	}
+	return p.call("Init", pluginInitArgs{
+		ConfigDir:       viper.GetString(ConfigDirKey),
+		GlobalConfigDir: viper.GetString(GlobalConfigDirKey),
+		Project:         viper.GetString("project"),
+	}, nil)
+}
+
+func (p *pluginClient) Auth(token string) error {
+	return p.call("Auth", M{"token": token}, nil)
+}
+
+func (p *pluginClient) Test() SimulationJob {
+	return pluginSimulation{pluginJob{p, JobTypeSimulation}}
+}
+
+func (p *pluginClient) Build() BuildJob {
+	return pluginBuild{pluginJob{p, JobTypeBuild}}
+}
+
+func (p *pluginClient) Deployment() DeploymentJob {
+	return pluginDeployment{pluginJob{p, JobTypeDeployment}}
+}
+
+func (p *pluginClient) Project() ProjectConfig {
+	return unsupportedProject{}
+}
+
+func (p *pluginClient) Graph() Graph {
+	return unsupportedGraph{}
+}
+
+var _ Job = pluginJob{}
+var _ BuildJob = pluginBuild{}
+var _ SimulationJob = pluginSimulation{}
+var _ DeploymentJob = pluginDeployment{}
+
+// pluginJob is a job type of a provider plugin.
+type pluginJob struct {
+	*pluginClient
+	jobType string
+}
+
+func (j pluginJob) Start(args Args) (string, error) {
+	var id string
+	err := j.call("Start", pluginJobArgs{Type: j.jobType, Args: args}, &id)
+	return id, err
+}
+
+// The typed start methods send positional args, as the plugin
+// protocol is unchanged.
+
+// pluginBuild is the build job type of a provider plugin.
+type pluginBuild struct{ pluginJob }
+
+func (j pluginBuild) StartBuild(opts BuildOptions) (string, error) {
+	return j.Start(opts.args())
+}
+
+// pluginSimulation is the simulation job type of a provider plugin.
+type pluginSimulation struct{ pluginJob }
+
+func (j pluginSimulation) StartSimulation(opts SimulationOptions) (string, error) {
+	return j.Start(opts.args())
+}
+
+// pluginDeployment is the deployment job type of a provider plugin.
+type pluginDeployment struct{ pluginJob }
+
+func (j pluginDeployment) StartDeployment(opts DeploymentOptions) (string, error) {
+	return j.Start(opts.args())
+}
+
+func (j pluginJob) Stop(id string) error {
+	return j.call("Stop", pluginJobArgs{Type: j.jobType, ID: id}, nil)
+}
+
+func (j pluginJob) Status(id string) Status {
+	var status Status
+	err := j.call("Status", pluginJobArgs{Type: j.jobType, ID: id}, &status)
+	if err == errUnsupported {
+		// as for job types a provider does not support.
+		return ""
+	}
+	if err != nil {
+		return StatusErrored
+	}
+	return status.upper()
+}
+
+func (j pluginJob) List(filter M) (printer.Table, error) {
+	var reply pluginTable
+	if err := j.call("List", pluginJobArgs{Type: j.jobType, Filter: filter}, &reply); err != nil {
+		return printer.Table{}, err
+	}
+	var table printer.Table
+	for _, col := range reply.Columns {
+		title := col.Title
+		if title == "" {
+			title = col.Name
+		}
+		table.Header = append(table.Header, printer.Column{Name: col.Name, Title: title})
+	}
+	for _, row := range reply.Rows {
+		table.Body = append(table.Body, printer.Row(row))
+	}
+	return table, nil
+}
+
+// Log writes the log of a job to writer, polling the plugin until
+// the log is done.
+func (j pluginJob) Log(id string, writer io.Writer) error {
+	var offset int64
+	for {
+		var reply pluginLog
+		if err := j.call("Log", pluginJobArgs{Type: j.jobType, ID: id, Offset: offset}, &reply); err != nil {
+			return err
+		}
+		n, err := io.WriteString(writer, reply.Data)
+		if err != nil {
+			return err
+		}
+		offset += int64(n)
+		if reply.Done {
+			return nil
+		}
+		if n == 0 {
+			time.Sleep(localPollInterval)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package reco
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os/exec"
+	"testing"
+)
+
+// TestProvider is a provider plugin served in process.
+type TestProvider struct {
+	log string
+}
+
+// TestJobArgs are the plugin job arguments as decoded by a plugin.
+type TestJobArgs struct {
+	Type   string                 `json:"type"`
+	ID     string                 `json:"id"`
+	Args   []interface{}          `json:"args"`
+	Filter map[string]interface{} `json:"filter"`
+	Offset int                    `json:"offset"`
+}
+
+// TestLog is a plugin log reply.
+type TestLog struct {
+	Data string `json:"data"`
+	Done bool   `json:"done"`
+}
+
+func (p *TestProvider) Auth(args map[string]string, reply *interface{}) error {
+	if args["token"] == "" {
+		return errors.New("token required")
+	}
+	return nil
+}
+
+func (p *TestProvider) Start(args TestJobArgs, id *string) error {
+	if args.Type != JobTypeSimulation {
+		return errors.New(pluginUnsupported)
+	}
+	*id = "sim-" + args.Args[1].(string)
+	return nil
+}
+
+func (p *TestProvider) Status(args TestJobArgs, status *string) error {
+	if args.Type != JobTypeSimulation {
+		return errors.New(pluginUnsupported)
+	}
+	*status = "completed"
+	return nil
+}
+
+func (p *TestProvider) List(args TestJobArgs, table *map[string]interface{}) error {
+	*table = map[string]interface{}{
+		"columns": []map[string]string{{"name": "id", "title": "simulation id"}, {"name": "status"}},
+		"rows":    [][]interface{}{{"sim-1", args.Filter["status"]}},
+	}
+	return nil
+}
+
+func (p *TestProvider) Log(args TestJobArgs, log *TestLog) error {
+	// return the log in two parts.
+	end := args.Offset + 6
+	if end >= len(p.log) {
+		end = len(p.log)
+		log.Done = true
+	}
+	log.Data = p.log[args.Offset:end]
+	return nil
+}
+
+func testPluginClient(t *testing.T) *pluginClient {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Provider", &TestProvider{log: "line 1\nline 2\n"}); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	go server.ServeCodec(jsonrpc.NewServerCodec(serverConn))
+	client := &pluginClient{}
+	client.connect(clientConn)
+	return client
+}
+
+func TestPluginClient(t *testing.T) {
+	client := testPluginClient(t)
+
+	// null results are accepted.
+	if err := client.Auth("token"); err != nil {
+		t.Error(err)
+	}
+	if err := client.Auth(""); err == nil || err.Error() != "token required" {
+		t.Errorf("expected plugin error, got %v", err)
+	}
+
+	id, err := client.Test().Start(Args{".", "test-addition", []string{"-n", "1"}})
+	if err != nil || id != "sim-test-addition" {
+		t.Errorf("unexpected start result %q %v", id, err)
+	}
+	if _, err := client.Build().Start(Args{"."}); err != errUnsupported {
+		t.Errorf("expected errUnsupported, got %v", err)
+	}
+	id, err = client.Test().StartSimulation(SimulationOptions{SourceDir: ".", Command: "test-addition"})
+	if err != nil || id != "sim-test-addition" {
+		t.Errorf("unexpected typed start result %q %v", id, err)
+	}
+	if _, err := client.Build().StartBuild(BuildOptions{SourceDir: "."}); err != errUnsupported {
+		t.Errorf("expected errUnsupported, got %v", err)
+	}
+	if err := client.Test().Stop(id); err == nil {
+		t.Error("expected error for method missing from plugin")
+	}
+	if status := client.Test().Status(id); status != StatusCompleted {
+		t.Errorf("unexpected status %q", status)
+	}
+	if status := client.Build().Status("build-1"); status != "" {
+		t.Errorf("expected no status from an unsupported method, got %q", status)
+	}
+
+	table, err := client.Test().List(M{"status": "completed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Header) != 2 || table.Header[1].Title != "status" || table.Value(0, "status") != "completed" {
+		t.Errorf("unexpected table %+v", table)
+	}
+
+	var log bytes.Buffer
+	if err := client.Test().Log(id, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.String() != "line 1\nline 2\n" {
+		t.Errorf("unexpected log %q", log.String())
+	}
+}
+
+func TestPluginClientClose(t *testing.T) {
+	// cat exits once its stdin is closed.
+	path, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat is not installed")
+	}
+	client := &pluginClient{path: path}
+	if err := client.start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("plugin did not exit cleanly: %v", err)
+	}
+	if client.cmd.ProcessState == nil || !client.cmd.ProcessState.Exited() {
+		t.Error("plugin process was not waited for")
+	}
+	if err := (&pluginClient{}).Close(); err != nil {
+		t.Errorf("closing a plugin client never started failed: %v", err)
+	}
+}
+
+func TestNewProviderClient(t *testing.T) {
+	if _, ok := mustProviderClient(t, "").(*clientImpl); !ok {
+		t.Error("expected platform client by default")
+	}
+	if _, ok := mustProviderClient(t, ProviderLocal).(*localClient); !ok {
+		t.Error("expected local client")
+	}
+	if _, err := NewProviderClient("no-such-provider"); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func mustProviderClient(t *testing.T, name string) Client {
+	client, err := NewProviderClient(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
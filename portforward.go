@@ -0,0 +1,309 @@
+package reco
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// PortForwarder forwards local ports to a running deployment.
+type PortForwarder interface {
+	// Forward forwards connections to the local ports of mappings to
+	// the deployment, until stop is closed or the deployment finishes.
+	// It returns the connection stats of each mapping.
+	Forward(id string, mappings []PortMapping, stop <-chan struct{}) ([]ForwardStats, error)
+}
+
+var _ PortForwarder = deploymentJob{}
+
+// PortMapping maps a local port to a deployment port.
+type PortMapping struct {
+	Local  int
+	Remote int
+}
+
+func (m PortMapping) String() string {
+	return fmt.Sprintf("%d -> %d", m.Local, m.Remote)
+}
+
+// ParsePortMappings pairs remote ports with local ports. A remote port
+// may also be given as local:remote. Remote ports without a local port
+// are forwarded from the same local port.
+func ParsePortMappings(remotes, locals []string) ([]PortMapping, error) {
+	if len(locals) > len(remotes) {
+		return nil, errors.New("each local port must have a deployment port")
+	}
+	mappings := make([]PortMapping, len(remotes))
+	for i, remote := range remotes {
+		local := remote
+		if j := strings.Index(remote, ":"); j >= 0 {
+			local, remote = remote[:j], remote[j+1:]
+			if i < len(locals) {
+				return nil, fmt.Errorf("port %s:%s already has a local port", local, remote)
+			}
+		} else if i < len(locals) {
+			local = locals[i]
+		}
+		var err error
+		if mappings[i].Remote, err = parsePort(remote); err != nil {
+			return nil, err
+		}
+		// only local ports may be 0, to be chosen by the system.
+		if mappings[i].Remote == 0 {
+			return nil, fmt.Errorf("invalid deployment port '%s'", remote)
+		}
+		if mappings[i].Local, err = parsePort(local); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port '%s'", s)
+	}
+	return port, nil
+}
+
+// ForwardStats are the connection stats of a forwarded port.
+type ForwardStats struct {
+	Mapping     PortMapping
+	Connections int
+	Failed      int
+	// Sent and Received are the bytes sent to and received from the
+	// deployment.
+	Sent     int64
+	Received int64
+}
+
+// ForwardStatsTable returns a table of forwarded port stats.
+func ForwardStatsTable(stats []ForwardStats) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "local", Title: "local port"},
+			{Name: "remote", Title: "deployment port"},
+			{Name: "connections", Title: "connections"},
+			{Name: "failed", Title: "failed"},
+			{Name: "sent", Title: "bytes sent"},
+			{Name: "received", Title: "bytes received"},
+		},
+	}
+	for _, s := range stats {
+		table.Body = append(table.Body, printer.Row{
+			s.Mapping.Local,
+			s.Mapping.Remote,
+			s.Connections,
+			s.Failed,
+			s.Sent,
+			s.Received,
+		})
+	}
+	return table
+}
+
+// portForward forwards connections from a local listener to a
+// remote address.
+type portForward struct {
+	listener net.Listener
+	remote   string
+
+	sync.Mutex
+	stats ForwardStats
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+	// closed is set by close, after which connections are refused.
+	closed bool
+}
+
+// listenForward listens on the local port of mapping, forwarding
+// connections to the remote port on host.
+func listenForward(host string, mapping PortMapping) (*portForward, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(mapping.Local)))
+	if err != nil {
+		return nil, err
+	}
+	// the local port is chosen by the system if 0.
+	mapping.Local = listener.Addr().(*net.TCPAddr).Port
+	return &portForward{
+		listener: listener,
+		remote:   net.JoinHostPort(host, strconv.Itoa(mapping.Remote)),
+		stats:    ForwardStats{Mapping: mapping},
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// serve accepts connections until the listener is closed.
+func (f *portForward) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.Lock()
+		if f.closed {
+			f.Unlock()
+			conn.Close()
+			return
+		}
+		f.wg.Add(1)
+		f.Unlock()
+		go f.forward(conn)
+	}
+}
+
+func (f *portForward) forward(local net.Conn) {
+	defer f.wg.Done()
+	defer local.Close()
+	remote, err := net.DialTimeout("tcp", f.remote, 10*time.Second)
+	f.Lock()
+	if err != nil {
+		f.stats.Failed++
+		f.Unlock()
+		logger.Error.Printf("port %d: %v", f.stats.Mapping.Local, err)
+		return
+	}
+	if f.closed {
+		// connected after close closed the open connections.
+		f.Unlock()
+		remote.Close()
+		return
+	}
+	f.stats.Connections++
+	f.conns[local] = struct{}{}
+	f.conns[remote] = struct{}{}
+	f.Unlock()
+	defer func() {
+		f.Lock()
+		delete(f.conns, local)
+		delete(f.conns, remote)
+		f.Unlock()
+		remote.Close()
+	}()
+
+	var wg sync.WaitGroup
+	var sent, received int64
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		sent, _ = io.Copy(remote, local)
+		closeWrite(remote)
+	}()
+	go func() {
+		defer wg.Done()
+		received, _ = io.Copy(local, remote)
+		closeWrite(local)
+	}()
+	wg.Wait()
+
+	f.Lock()
+	f.stats.Sent += sent
+	f.stats.Received += received
+	f.Unlock()
+}
+
+// closeWrite half closes conn, so the peer sees the end of the stream
+// while responses are still read.
+func closeWrite(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+		return
+	}
+	conn.Close()
+}
+
+// close stops forwarding, closing open connections, and returns
+// the stats.
+func (f *portForward) close() ForwardStats {
+	f.listener.Close()
+	f.Lock()
+	f.closed = true
+	for conn := range f.conns {
+		conn.Close()
+	}
+	f.Unlock()
+	f.wg.Wait()
+	return f.stats
+}
+
+// forwardPorts forwards the local ports of mappings to host until
+// stop is closed.
+func forwardPorts(host string, mappings []PortMapping, stop <-chan struct{}) ([]ForwardStats, error) {
+	var forwards []*portForward
+	closeAll := func() []ForwardStats {
+		stats := make([]ForwardStats, len(forwards))
+		for i, f := range forwards {
+			stats[i] = f.close()
+		}
+		return stats
+	}
+	for _, mapping := range mappings {
+		f, err := listenForward(host, mapping)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		forwards = append(forwards, f)
+		logger.Info.Printf("Forwarding 127.0.0.1:%d -> %s", f.stats.Mapping.Local, f.remote)
+		go f.serve()
+	}
+	<-stop
+	return closeAll(), nil
+}
+
+// Forward waits for the deployment to have an IP address, then forwards
+// the ports of mappings to it.
+func (p deploymentJob) Forward(id string, mappings []PortMapping, stop <-chan struct{}) ([]ForwardStats, error) {
+	logger.Info.Println("Waiting for deployment to start")
+	var job jobInfo
+	for {
+		var err error
+		if job, err = p.getJob(JobTypeDeployment, id); err != nil {
+			return nil, err
+		}
+		if job.IPAddress != "" || job.IsCompleted() {
+			break
+		}
+		select {
+		case <-stop:
+			// stopped before forwarding any connection.
+			return nil, nil
+		case <-time.After(waitInterval):
+		}
+	}
+	if job.IsCompleted() {
+		return nil, errors.New("instance has shutdown")
+	}
+
+	// stop forwarding when the deployment finishes.
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-done:
+				return
+			case <-time.After(waitInterval):
+			}
+			if job, err := p.getJob(JobTypeDeployment, id); err == nil && job.IsCompleted() {
+				logger.Info.Println("Deployment has finished")
+				return
+			}
+		}
+	}()
+	stats, err := forwardPorts(job.IPAddress, mappings, finished)
+	close(done)
+	return stats, err
+}
@@ -0,0 +1,160 @@
+package reco
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParsePortMappings(t *testing.T) {
+	tests := []struct {
+		remotes, locals []string
+		mappings        []PortMapping
+		err             bool
+	}{
+		{[]string{"8080"}, nil, []PortMapping{{8080, 8080}}, false},
+		{[]string{"8080"}, []string{"9000"}, []PortMapping{{9000, 8080}}, false},
+		{[]string{"8080", "50051"}, []string{"9000"}, []PortMapping{{9000, 8080}, {50051, 50051}}, false},
+		{[]string{"9000:8080"}, nil, []PortMapping{{9000, 8080}}, false},
+		{[]string{"9000:8080"}, []string{"9001"}, nil, true},
+		{[]string{"8080"}, []string{"9000", "9001"}, nil, true},
+		{[]string{"http"}, nil, nil, true},
+		{[]string{"70000"}, nil, nil, true},
+		{[]string{"0"}, nil, nil, true},
+		{[]string{"0:8080"}, nil, []PortMapping{{0, 8080}}, false},
+	}
+	for i, test := range tests {
+		mappings, err := ParsePortMappings(test.remotes, test.locals)
+		if (err != nil) != test.err {
+			t.Errorf("test %d: unexpected error %v", i, err)
+			continue
+		}
+		if len(mappings) != len(test.mappings) {
+			t.Errorf("test %d: expected %v, got %v", i, test.mappings, mappings)
+			continue
+		}
+		for j := range mappings {
+			if mappings[j] != test.mappings[j] {
+				t.Errorf("test %d: expected %v, got %v", i, test.mappings, mappings)
+			}
+		}
+	}
+}
+
+func TestPortForward(t *testing.T) {
+	// an echo server standing in for the deployment.
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	remote := echo.Addr().(*net.TCPAddr).Port
+	f, err := listenForward("127.0.0.1", PortMapping{Local: 0, Remote: remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go f.serve()
+
+	conn, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(f.stats.Mapping.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	conn.(*net.TCPConn).CloseWrite()
+	b, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if string(b) != "hello" {
+		t.Errorf("expected echo of hello, got %q", b)
+	}
+
+	stats := f.close()
+	if stats.Connections != 1 || stats.Failed != 0 || stats.Sent != 5 || stats.Received != 5 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestPortForwardClosed(t *testing.T) {
+	remote, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+	f, err := listenForward("127.0.0.1", PortMapping{Local: 0, Remote: remote.Addr().(*net.TCPAddr).Port})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.close()
+
+	// a connection accepted as the forward closes is not forwarded.
+	local, peer := net.Pipe()
+	defer peer.Close()
+	f.wg.Add(1)
+	go f.forward(local)
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection forwarded after close")
+	}
+	if stats := f.close(); stats.Connections != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestForwardStoppedWhileWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/deployments/dep1" {
+			json.NewEncoder(w).Encode(M{"value": M{"id": "dep1", "job": M{"events": []M{
+				{"timestamp": "2017-01-01T10:00:00Z", "status": "QUEUED"},
+			}}}})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	stop := make(chan struct{})
+	close(stop)
+	job := deploymentJob{&clientImpl{platformServer: server.URL, Username: "user", Token: "token"}}
+	done := make(chan error, 1)
+	go func() {
+		_, err := job.Forward("dep1", []PortMapping{{Remote: 80}}, stop)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(waitInterval / 2):
+		t.Error("Forward did not return once stopped while waiting for the deployment")
+	}
+}
@@ -0,0 +1,70 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Color is an ANSI terminal color.
+type Color int
+
+// Supported colors.
+const (
+	NoColor Color = 0
+	Bold    Color = 1
+	Red     Color = 31
+	Green   Color = 32
+	Yellow  Color = 33
+)
+
+// Colors enables colored output. It is enabled by default
+// when stdout is a terminal and NO_COLOR is not set.
+var Colors = colorSupported()
+
+func colorSupported() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	// legacy windows consoles do not support ANSI escape codes.
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorize returns text in color c if colors are enabled.
+func Colorize(text string, c Color) string {
+	if !Colors || c == NoColor {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, text)
+}
+
+// Colored is a table value displayed in color. Its raw
+// value is uncolored.
+type Colored struct {
+	Value string
+	Color Color
+}
+
+// String returns the uncolored value.
+func (c Colored) String() string {
+	return c.Value
+}
+
+// Highlighted is a table value displayed highlighted when
+// colors are enabled.
+type Highlighted struct {
+	Value interface{}
+}
+
+// String returns the unhighlighted value.
+func (h Highlighted) String() string {
+	return fmt.Sprint(h.Value)
+}
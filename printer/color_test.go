@@ -0,0 +1,46 @@
+package printer
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+)
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestColoredAlignment(t *testing.T) {
+	table := Table{
+		Header: []Column{{Name: "status", Title: "status"}, {Name: "id", Title: "id"}},
+		Body: []Row{
+			{Colored{Value: "completed", Color: Green}, "abc"},
+			{Colored{Value: "queued", Color: Yellow}, "def"},
+			{"started", "ghi"},
+		},
+	}
+	defer func(colors bool) { Colors = colors }(Colors)
+
+	var plain, colored bytes.Buffer
+	Colors = false
+	if err := Fprint(&plain, table); err != nil {
+		t.Fatal(err)
+	}
+	Colors = true
+	if err := Fprint(&colored, table); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ansi.MatchString(colored.String()) {
+		t.Error("expected colored output")
+	}
+	if stripped := ansi.ReplaceAllString(colored.String(), ""); stripped != plain.String() {
+		t.Errorf("colors changed alignment, expected\n%s\ngot\n%s", plain.String(), stripped)
+	}
+}
+
+func TestColoredRawValue(t *testing.T) {
+	defer func(colors bool) { Colors = colors }(Colors)
+	Colors = true
+	if v := rawString(Colored{Value: "errored", Color: Red}); v != "errored" {
+		t.Errorf("expected uncolored raw value, got %q", v)
+	}
+}
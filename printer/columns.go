@@ -0,0 +1,123 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Select returns the table with only the named columns, in the
+// given order. Hidden columns are displayed if selected.
+func (t Table) Select(names []string) (Table, error) {
+	var selected Table
+	var indexes []int
+	for _, name := range names {
+		i := t.columnIndex(strings.TrimSpace(name))
+		if i < 0 {
+			return selected, fmt.Errorf("unknown column '%s'. Available columns are %s", name, strings.Join(t.names(), ", "))
+		}
+		col := t.Header[i]
+		col.Hidden = false
+		selected.Header = append(selected.Header, col)
+		indexes = append(indexes, i)
+	}
+	for _, row := range t.Body {
+		selected.Body = append(selected.Body, t.pick(row, indexes))
+	}
+	return selected, nil
+}
+
+// visible returns the table without its hidden columns.
+func (t Table) visible() Table {
+	var names []string
+	for _, col := range t.Header {
+		if !col.Hidden {
+			names = append(names, col.Name)
+		}
+	}
+	if len(names) == len(t.Header) {
+		return t
+	}
+	// names are known to exist, error is impossible.
+	v, _ := t.Select(names)
+	return v
+}
+
+// Value returns the value of column in row i, or nil if
+// there is no such column.
+func (t Table) Value(i int, column string) interface{} {
+	if index := t.columnIndex(column); index >= 0 && i < len(t.Body) {
+		return t.Body[i].At(index)
+	}
+	return nil
+}
+
+func (t Table) columnIndex(name string) int {
+	for i, col := range t.Header {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t Table) pick(row Row, indexes []int) Row {
+	picked := make(Row, len(indexes))
+	for i, index := range indexes {
+		picked[i] = row.At(index)
+	}
+	return picked
+}
+
+// FprintTemplate prints each row of the table to a writer using
+// a Go template, e.g. '{{.ID}} {{.Status}}'. All columns, including
+// hidden ones, are available as fields named after the column name
+// e.g. column "build_id" is field BuildID. Fields hold raw values, as
+// in JSON output; use the human function with a field name for the
+// table representation e.g. '{{human "Started"}}'.
+func FprintTemplate(w io.Writer, table Table, text string) error {
+	var row Row
+	human := func(field string) (string, error) {
+		for i, col := range table.Header {
+			if fieldName(col.Name) == field {
+				return humanValue(row.At(i)), nil
+			}
+		}
+		return "", fmt.Errorf("no field %s", field)
+	}
+	tmpl, err := template.New("format").Funcs(template.FuncMap{
+		"human": human,
+	}).Parse(text)
+	if err != nil {
+		return err
+	}
+	for _, row = range table.Body {
+		fields := make(map[string]interface{})
+		for i, col := range table.Header {
+			fields[fieldName(col.Name)] = rawValue(row.At(i))
+		}
+		if err := tmpl.Execute(w, fields); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldName converts a column name to a template field name
+// e.g. "build_id" to "BuildID".
+func fieldName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		switch part {
+		case "id", "ip":
+			parts[i] = strings.ToUpper(part)
+		default:
+			parts[i] = strings.Title(part)
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,66 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+	table, err := testTable.Select([]string{"duration", "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Header) != 2 || table.Header[0].Name != "duration" || table.Header[1].Name != "id" {
+		t.Errorf("unexpected columns %v", table.Header)
+	}
+	if table.Body[0][1] != "abc" {
+		t.Errorf("expected id abc, got %v", table.Body[0][1])
+	}
+	if _, err := testTable.Select([]string{"unknown"}); err == nil {
+		t.Error("Select should fail for unknown columns")
+	}
+}
+
+func TestHiddenColumns(t *testing.T) {
+	table := Table{
+		Header: []Column{{Name: "id", Title: "id"}, {Name: "build_id", Title: "build id", Hidden: true}},
+		Body:   []Row{{"abc", "def"}},
+	}
+	var buf bytes.Buffer
+	if err := FprintFormat(&buf, table, FormatCSV); err != nil {
+		t.Error(err)
+	}
+	if buf.String() != "id\nabc\n" {
+		t.Errorf("hidden column printed: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := FprintTemplate(&buf, table, "{{.ID}} {{.BuildID}}"); err != nil {
+		t.Error(err)
+	}
+	if buf.String() != "abc def\n" {
+		t.Errorf("unexpected template output: %q", buf.String())
+	}
+}
+
+func TestTemplateRawValues(t *testing.T) {
+	table := Table{
+		Header: []Column{{Name: "id", Title: "id"}, {Name: "status", Title: "status"}, {Name: "duration", Title: "duration"}},
+		Body: []Row{
+			{"abc", Colored{Value: "completed", Color: Green}, 90 * time.Second},
+			{"def", Highlighted{Value: Colored{Value: "errored", Color: Red}}, time.Duration(0)},
+		},
+	}
+	var buf bytes.Buffer
+	text := `{{.ID}}{{if eq .Status "completed"}} ok {{.Duration}} {{human "Duration"}}{{end}}`
+	if err := FprintTemplate(&buf, table, text); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "abc ok 90 1m30s\ndef\n"; buf.String() != expected {
+		t.Errorf("Expected %q, found %q", expected, buf.String())
+	}
+	if err := FprintTemplate(&buf, table, `{{human "Unknown"}}`); err == nil {
+		t.Error("human of unknown field did not fail")
+	}
+}
@@ -0,0 +1,262 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Format is an output format for tables.
+type Format string
+
+// Supported output formats.
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+)
+
+// Formats lists the supported output formats.
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV}
+
+// ParseFormat parses an output format. An empty string
+// is the table format.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatTable, nil
+	}
+	for _, f := range Formats {
+		if Format(strings.ToLower(s)) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format '%s'. Supported formats are %s", s, formatNames())
+}
+
+func formatNames() string {
+	var names []string
+	for _, f := range Formats {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+// FprintFormat prints the table to a writer in format.
+// Structured formats carry raw values: RFC 3339 times
+// and durations in seconds.
+func FprintFormat(w io.Writer, table Table, format Format) error {
+	table = table.visible()
+	switch format {
+	case FormatTable, "":
+		return Fprint(w, table)
+	case FormatJSON:
+		b, err := json.MarshalIndent(table.records(), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case FormatYAML:
+		b, err := yaml.Marshal(table.records())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case FormatCSV:
+		return fprintDelimited(w, table, ',')
+	case FormatTSV:
+		return fprintDelimited(w, table, '\t')
+	}
+	return fmt.Errorf("unknown output format '%s'", format)
+}
+
+func fprintDelimited(w io.Writer, table Table, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(table.names()); err != nil {
+		return err
+	}
+	for _, row := range table.Body {
+		record := make([]string, len(table.Header))
+		for i := range table.Header {
+			record[i] = rawString(row.At(i))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// At returns the value at column i.
+func (r Row) At(i int) interface{} {
+	if len(r) <= i {
+		return nil
+	}
+	return r[i]
+}
+
+func (t Table) titles() []string {
+	titles := make([]string, len(t.Header))
+	for i, col := range t.Header {
+		titles[i] = col.Title
+	}
+	return titles
+}
+
+func (t Table) names() []string {
+	names := make([]string, len(t.Header))
+	for i, col := range t.Header {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// humanized returns the table body as human readable strings.
+func (t Table) humanized() [][]string {
+	body := make([][]string, len(t.Body))
+	for i, row := range t.Body {
+		body[i] = make([]string, len(t.Header))
+		for j := range t.Header {
+			body[i][j] = humanValue(row.At(j))
+		}
+	}
+	return body
+}
+
+func (t Table) records() []record {
+	records := make([]record, len(t.Body))
+	for i, row := range t.Body {
+		records[i] = record{columns: t.Header, row: row}
+	}
+	return records
+}
+
+// record is a table row keyed by column names. It preserves
+// column order when marshalled.
+type record struct {
+	columns []Column
+	row     Row
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, col := range r.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(col.Name)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(rawValue(r.row.At(i)))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (r record) MarshalYAML() (interface{}, error) {
+	var m yaml.MapSlice
+	for i, col := range r.columns {
+		m = append(m, yaml.MapItem{Key: col.Name, Value: rawValue(r.row.At(i))})
+	}
+	return m, nil
+}
+
+// humanValue returns the human readable form of v.
+func humanValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return "-"
+		}
+		return humanize.Time(v)
+	case time.Duration:
+		if v <= 0 {
+			return "-"
+		}
+		// round to the nearest second.
+		return (v / time.Second * time.Second).String()
+	case Marker:
+		if v {
+			return "[*]"
+		}
+		return ""
+	case Percent:
+		return fmt.Sprintf("%.2f%%", float64(v))
+	case Change:
+		return fmt.Sprintf("%+d", int(v))
+	case Colored:
+		return Colorize(v.Value, v.Color)
+	case Highlighted:
+		return Colorize(humanValue(v.Value), Bold)
+	}
+	return fmt.Sprint(v)
+}
+
+// rawValue returns the machine readable form of v. Unset
+// times and durations are nil.
+func rawValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case time.Duration:
+		if v <= 0 {
+			return nil
+		}
+		return v.Seconds()
+	case Marker:
+		return bool(v)
+	case Percent:
+		return float64(v)
+	case Change:
+		return int(v)
+	case Colored:
+		return v.Value
+	case Highlighted:
+		return rawValue(v.Value)
+	}
+	return v
+}
+
+// rawString returns the raw value of v as a string.
+func rawString(v interface{}) string {
+	switch v := rawValue(v).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
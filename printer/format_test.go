@@ -0,0 +1,72 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+var testTable = Table{
+	Header: []Column{
+		{Name: "id", Title: "build id"},
+		{Name: "started", Title: "started"},
+		{Name: "duration", Title: "duration"},
+		{Name: "active", Title: "active"},
+	},
+	Body: []Row{
+		{"abc", time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC), 90 * time.Second, Marker(true)},
+		{"def", time.Time{}, time.Duration(0), Marker(false)},
+	},
+}
+
+func TestFprintFormat(t *testing.T) {
+	expected := map[Format]string{
+		FormatJSON: `[
+  {
+    "id": "abc",
+    "started": "2026-09-01T10:00:00Z",
+    "duration": 90,
+    "active": true
+  },
+  {
+    "id": "def",
+    "started": null,
+    "duration": null,
+    "active": false
+  }
+]
+`,
+		FormatYAML: `- id: abc
+  started: "2026-09-01T10:00:00Z"
+  duration: 90
+  active: true
+- id: def
+  started: null
+  duration: null
+  active: false
+`,
+		FormatCSV: "id,started,duration,active\nabc,2026-09-01T10:00:00Z,90,true\ndef,,,false\n",
+		FormatTSV: "id\tstarted\tduration\tactive\nabc\t2026-09-01T10:00:00Z\t90\ttrue\ndef\t\t\tfalse\n",
+	}
+	for format, output := range expected {
+		var buf bytes.Buffer
+		if err := FprintFormat(&buf, testTable, format); err != nil {
+			t.Error(err)
+		}
+		if buf.String() != output {
+			t.Errorf("%s output mismatch, expected\n%s\ngot\n%s", format, output, buf.String())
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(""); err != nil || f != FormatTable {
+		t.Error("ParseFormat should default to table")
+	}
+	if f, err := ParseFormat("JSON"); err != nil || f != FormatJSON {
+		t.Error("ParseFormat should be case insensitive")
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat should reject unknown formats")
+	}
+}
@@ -14,10 +14,32 @@ import (
 
 // Table is the table to print.
 type Table struct {
-	Header []string   // Column titles
-	Body   [][]string // Rows data
+	Header []Column // Columns
+	Body   []Row    // Rows data
 }
 
+// Column is a table column.
+type Column struct {
+	Name   string // Key used for structured output e.g. "id"
+	Title  string // Title displayed in table output e.g. "build id"
+	Hidden bool   // Only displayed when selected
+}
+
+// Row is a table row. It holds the raw value of each column
+// in column order, e.g. time.Time for times and time.Duration
+// for durations. Values are humanized for table output.
+type Row []interface{}
+
+// Marker is a flag displayed as "[*]" in table output when set.
+type Marker bool
+
+// Percent is a percentage displayed with two decimal places
+// in table output.
+type Percent float64
+
+// Change is a difference displayed with its sign in table output.
+type Change int
+
 // Empty checks if the table is empty.
 func (t Table) Empty() bool {
 	return len(t.Body) == 0
@@ -52,7 +74,13 @@ func Print(table Table) error {
 		return err
 	}
 
-	cmd := exec.Command(command)
+	var cmd *exec.Cmd
+	if command == "less" {
+		// display colors.
+		cmd = exec.Command(command, "-R")
+	} else {
+		cmd = exec.Command(command)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = &buf
@@ -61,17 +89,18 @@ func Print(table Table) error {
 
 // Fprint prints the table to a writer.
 func Fprint(w io.Writer, table Table) error {
+	table = table.visible()
 	if _, err := fmt.Fprintln(w); err != nil {
 		return err
 	}
 	tWriter := tablewriter.NewWriter(w)
-	tWriter.SetHeader(table.Header)
+	tWriter.SetHeader(table.titles())
 	tWriter.SetHeaderLine(false)
 	tWriter.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
 	tWriter.SetCenterSeparator(" ")
 	tWriter.SetColumnSeparator("    ")
 	tWriter.SetAlignment(tablewriter.ALIGN_LEFT)
-	tWriter.AppendBulk(table.Body) // Add Bulk Data
+	tWriter.AppendBulk(table.humanized()) // Add Bulk Data
 	tWriter.Render()
 	if _, err := fmt.Fprintln(w); err != nil {
 		return err
@@ -0,0 +1,20 @@
+package printer
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		line   string
+	}{
+		{nil, ""},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]float64{10, 80, 10}, "▁█▁"},
+		{[]float64{5, 5}, "▅▅"},
+	}
+	for _, test := range tests {
+		if line := Sparkline(test.values); line != test.line {
+			t.Errorf("Sparkline(%v) = %q, expected %q", test.values, line, test.line)
+		}
+	}
+}
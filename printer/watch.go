@@ -0,0 +1,60 @@
+package printer
+
+import (
+	"io"
+	"os"
+	"runtime"
+)
+
+// Redrawable checks if stdout is a terminal that supports
+// redrawing output in place.
+func Redrawable() bool {
+	return runtime.GOOS != "windows" && isTerminal(os.Stdout)
+}
+
+// ClearScreen clears the terminal and moves the cursor to the top.
+func ClearScreen(w io.Writer) error {
+	_, err := io.WriteString(w, "\x1b[H\x1b[2J")
+	return err
+}
+
+// Changed returns the indexes of rows whose column value differs from
+// the row with the same key in previous. Rows missing from a non empty
+// previous table are considered changed.
+func (t Table) Changed(previous Table, key, column string) []int {
+	if previous.Empty() {
+		return nil
+	}
+	keyIndex, colIndex := t.columnIndex(key), t.columnIndex(column)
+	prevKeyIndex, prevColIndex := previous.columnIndex(key), previous.columnIndex(column)
+	if keyIndex < 0 || colIndex < 0 || prevKeyIndex < 0 || prevColIndex < 0 {
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, row := range previous.Body {
+		values[rawString(row.At(prevKeyIndex))] = rawString(row.At(prevColIndex))
+	}
+	var changed []int
+	for i, row := range t.Body {
+		value, ok := values[rawString(row.At(keyIndex))]
+		if !ok || value != rawString(row.At(colIndex)) {
+			changed = append(changed, i)
+		}
+	}
+	return changed
+}
+
+// Highlight returns the table with rows highlighted.
+func (t Table) Highlight(rows []int) Table {
+	highlighted := Table{Header: t.Header, Body: make([]Row, len(t.Body))}
+	copy(highlighted.Body, t.Body)
+	for _, i := range rows {
+		row := make(Row, len(t.Body[i]))
+		for j, v := range t.Body[i] {
+			row[j] = Highlighted{Value: v}
+		}
+		highlighted.Body[i] = row
+	}
+	return highlighted
+}
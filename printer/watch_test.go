@@ -0,0 +1,28 @@
+package printer
+
+import "testing"
+
+func TestChanged(t *testing.T) {
+	header := []Column{{Name: "id"}, {Name: "status"}}
+	previous := Table{Header: header, Body: []Row{{"a", "queued"}, {"b", "started"}}}
+	current := Table{Header: header, Body: []Row{{"c", "queued"}, {"a", "started"}, {"b", "started"}}}
+
+	if changed := current.Changed(Table{}, "id", "status"); len(changed) != 0 {
+		t.Errorf("expected no changes on first refresh, got %v", changed)
+	}
+	changed := current.Changed(previous, "id", "status")
+	if len(changed) != 2 || changed[0] != 0 || changed[1] != 1 {
+		t.Errorf("expected rows 0 and 1 to change, got %v", changed)
+	}
+
+	highlighted := current.Highlight(changed)
+	if _, ok := highlighted.Body[1][0].(Highlighted); !ok {
+		t.Error("expected changed row to be highlighted")
+	}
+	if _, ok := highlighted.Body[2][0].(Highlighted); ok {
+		t.Error("expected unchanged row not to be highlighted")
+	}
+	if _, ok := current.Body[1][0].(Highlighted); ok {
+		t.Error("Highlight modified the original table")
+	}
+}
@@ -0,0 +1,149 @@
+package reco
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ProbeTCP probes succeed when a TCP connection is accepted.
+	ProbeTCP = "tcp"
+	// ProbeHTTP probes succeed when an HTTP GET gets the expected response.
+	ProbeHTTP = "http"
+
+	// probeAttemptTimeout is the timeout of a single probe attempt.
+	probeAttemptTimeout = 5 * time.Second
+	// probeBodyLimit is the most of a response body matched by a probe.
+	probeBodyLimit = 1 << 20
+)
+
+// Probe is a readiness check of a deployment.
+type Probe struct {
+	// Kind is ProbeTCP or ProbeHTTP.
+	Kind string `json:"kind"`
+	Port int    `json:"port"`
+	// Path, Status and Body apply to HTTP probes. Without Status, any
+	// 2xx or 3xx status is expected. Body is a regular expression the
+	// response body must match.
+	Path   string `json:"path,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Body   string `json:"body,omitempty"`
+	// Delay is the wait before the first attempt, once the deployment
+	// has an address.
+	Delay    time.Duration `json:"delay"`
+	Interval time.Duration `json:"interval"`
+	// Timeout is the time to wait for the deployment to be ready once
+	// it has started, or 0 to wait until it finishes. Time queued
+	// before starting is not counted.
+	Timeout time.Duration `json:"timeout"`
+}
+
+// DefaultProbe returns the default probe, dialing port 80.
+func DefaultProbe() Probe {
+	return Probe{
+		Kind:     ProbeTCP,
+		Port:     80,
+		Path:     "/",
+		Interval: 10 * time.Second,
+		Timeout:  10 * time.Minute,
+	}
+}
+
+// Validate checks the probe options.
+func (p Probe) Validate() error {
+	switch p.Kind {
+	case ProbeTCP:
+		if p.Status != 0 || p.Body != "" {
+			return errors.New("expected status and body require an http probe")
+		}
+	case ProbeHTTP:
+		if !strings.HasPrefix(p.Path, "/") {
+			return fmt.Errorf("invalid probe path '%s'. Paths start with /", p.Path)
+		}
+		if _, err := regexp.Compile(p.Body); err != nil {
+			return fmt.Errorf("invalid probe body regular expression: %v", err)
+		}
+	default:
+		return fmt.Errorf("invalid probe '%s'. Probes are %s or %s", p.Kind, ProbeTCP, ProbeHTTP)
+	}
+	if p.Port <= 0 || p.Port > 65535 {
+		return fmt.Errorf("invalid probe port %d", p.Port)
+	}
+	if p.Interval <= 0 {
+		return errors.New("probe interval must be positive")
+	}
+	return nil
+}
+
+func (p Probe) String() string {
+	if p.Kind == ProbeHTTP {
+		return fmt.Sprintf("http probe GET %s on port %d", p.Path, p.Port)
+	}
+	return fmt.Sprintf("tcp probe on port %d", p.Port)
+}
+
+// URL returns the URL of the probed port on host.
+func (p Probe) URL(host string) string {
+	path := p.Path
+	if p.Kind != ProbeHTTP || path == "" {
+		path = "/"
+	}
+	if p.Port != 80 {
+		host = net.JoinHostPort(host, strconv.Itoa(p.Port))
+	}
+	return "http://" + host + path
+}
+
+// Check probes host once, returning the latency of the attempt.
+func (p Probe) Check(host string) (time.Duration, error) {
+	start := time.Now()
+	var err error
+	if p.Kind == ProbeHTTP {
+		err = p.checkHTTP(host)
+	} else {
+		err = p.checkTCP(host)
+	}
+	return time.Since(start), err
+}
+
+func (p Probe) checkTCP(host string) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(p.Port)), probeAttemptTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p Probe) checkHTTP(host string) error {
+	client := http.Client{Timeout: probeAttemptTimeout}
+	resp, err := client.Get(p.URL(host))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case p.Status != 0 && resp.StatusCode != p.Status:
+		return fmt.Errorf("got status %d, expected %d", resp.StatusCode, p.Status)
+	case p.Status == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 400):
+		return fmt.Errorf("got status %d", resp.StatusCode)
+	}
+	if p.Body == "" {
+		return nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, probeBodyLimit))
+	if err != nil {
+		return err
+	}
+	if matched, _ := regexp.Match(p.Body, body); !matched {
+		return fmt.Errorf("body does not match '%s'", p.Body)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package reco
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProbeValidate(t *testing.T) {
+	valid := DefaultProbe()
+	if err := valid.Validate(); err != nil {
+		t.Errorf("default probe invalid: %v", err)
+	}
+	tests := []func(p *Probe){
+		func(p *Probe) { p.Kind = "udp" },
+		func(p *Probe) { p.Port = 0 },
+		func(p *Probe) { p.Interval = 0 },
+		func(p *Probe) { p.Status = 200 },
+		func(p *Probe) { p.Kind, p.Path = ProbeHTTP, "health" },
+		func(p *Probe) { p.Kind, p.Body = ProbeHTTP, "(" },
+	}
+	for i, modify := range tests {
+		p := DefaultProbe()
+		modify(&p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("test %d: expected invalid probe %+v", i, p)
+		}
+	}
+}
+
+func TestProbeCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	tests := []struct {
+		probe Probe
+		ready bool
+	}{
+		{Probe{Kind: ProbeTCP, Port: portNum}, true},
+		{Probe{Kind: ProbeHTTP, Port: portNum, Path: "/health"}, true},
+		{Probe{Kind: ProbeHTTP, Port: portNum, Path: "/"}, false},
+		{Probe{Kind: ProbeHTTP, Port: portNum, Path: "/", Status: 404}, true},
+		{Probe{Kind: ProbeHTTP, Port: portNum, Path: "/health", Body: `"status": "ok"`}, true},
+		{Probe{Kind: ProbeHTTP, Port: portNum, Path: "/health", Body: `"status": "starting"`}, false},
+	}
+	for i, test := range tests {
+		if _, err := test.probe.Check(host); (err == nil) != test.ready {
+			t.Errorf("test %d: expected ready %v, got %v", i, test.ready, err)
+		}
+	}
+}
+
+func TestProbeURL(t *testing.T) {
+	if url := DefaultProbe().URL("10.0.0.1"); url != "http://10.0.0.1/" {
+		t.Errorf("unexpected URL %s", url)
+	}
+	p := Probe{Kind: ProbeHTTP, Port: 8080, Path: "/health"}
+	if url := p.URL("10.0.0.1"); url != "http://10.0.0.1:8080/health" {
+		t.Errorf("unexpected URL %s", url)
+	}
+}
+
+func TestConnectTimeoutExcludesQueueing(t *testing.T) {
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	polls, started := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/deployments/dep1" {
+			http.NotFound(w, r)
+			return
+		}
+		polls++
+		deployment := M{"id": "dep1", "job": M{"events": []M{
+			{"timestamp": "2017-01-01T10:00:00Z", "status": "QUEUED"},
+		}}}
+		// queued for longer than the probe timeout.
+		if polls > 5 {
+			started++
+			deployment = M{"id": "dep1", "ip_address": "127.0.0.1", "job": M{"events": []M{
+				{"timestamp": "2017-01-01T10:00:00Z", "status": "QUEUED"},
+				{"timestamp": "2017-01-01T10:01:00Z", "status": "STARTED"},
+			}}}
+		}
+		json.NewEncoder(w).Encode(M{"value": deployment})
+	}))
+	defer server.Close()
+
+	job := deploymentJob{&clientImpl{platformServer: server.URL, Username: "user", Token: "token"}}
+	probe := Probe{Kind: ProbeTCP, Port: port, Interval: 30 * time.Millisecond, Timeout: 100 * time.Millisecond}
+	if err := job.Connect("dep1", probe, false); err == nil {
+		t.Error("Connect to a closed port did not fail")
+	}
+	if started == 0 {
+		t.Error("the probe timed out while the deployment was queued")
+	}
+}
@@ -57,25 +57,27 @@ func (p platformProject) List(filter M) (printer.Table, error) {
 	active := false
 	// TODO remove set when platform handles distinct names
 	set := make(map[string]struct{})
-	var body [][]string
+	var body []printer.Row
 	for _, v := range jsonResp.Value {
 		set[v.Name] = struct{}{}
-		row := []string{v.Name}
 
 		// active
-		if v.ID == p.p.ProjectID {
-			row = append(row, "[*]")
+		isActive := v.ID == p.p.ProjectID
+		if isActive {
 			active = true
 		}
-		body = append(body, row)
+		body = append(body, printer.Row{v.Name, printer.Marker(isActive)})
 	}
 
 	table = printer.Table{
-		Header: []string{"name", "active"},
-		Body:   body,
+		Header: []printer.Column{
+			{Name: "name", Title: "name"},
+			{Name: "active", Title: "active"},
+		},
+		Body: body,
 	}
 	if !active {
-		logger.Std.Println(errNoActiveProject)
+		logger.Info.Println(errNoActiveProject)
 	}
 	return table, nil
 }
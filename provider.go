@@ -0,0 +1,83 @@
+package reco
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// ProviderKey is the config key of the provider to use.
+	ProviderKey = "provider"
+	// ProviderPlatform is the reconfigure.io platform provider.
+	ProviderPlatform = "platform"
+	// ProviderLocal is the provider running simulations locally.
+	ProviderLocal = "local"
+	// pluginPrefix is the executable name prefix of provider plugins
+	// found in PATH e.g. reco-provider-farm for provider "farm".
+	pluginPrefix = "reco-provider-"
+)
+
+// ProviderFactory creates the client of a provider.
+type ProviderFactory func() (Client, error)
+
+var (
+	providersMu sync.Mutex
+	providers   = make(map[string]ProviderFactory)
+)
+
+func init() {
+	RegisterProvider(ProviderPlatform, func() (Client, error) { return NewClient(), nil })
+	RegisterProvider(ProviderLocal, func() (Client, error) { return NewLocalClient(), nil })
+}
+
+// RegisterProvider registers a compiled in provider. It panics if
+// a provider is already registered with the name.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if _, ok := providers[name]; ok {
+		panic("reco: provider " + name + " registered twice")
+	}
+	providers[name] = factory
+}
+
+// Providers returns the names of the registered providers.
+func Providers() []string {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	var names []string
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProviderClient creates the client of a provider. Registered
+// providers take precedence over plugins. Plugins are executables
+// set in config as providers.<name>, or named reco-provider-<name>
+// in PATH. An empty name is the platform provider.
+func NewProviderClient(name string) (Client, error) {
+	if name == "" {
+		name = ProviderPlatform
+	}
+	providersMu.Lock()
+	factory, ok := providers[name]
+	providersMu.Unlock()
+	if ok {
+		return factory()
+	}
+	path := viper.GetString("providers." + name)
+	if path == "" {
+		var err error
+		if path, err = exec.LookPath(pluginPrefix + name); err != nil {
+			return nil, fmt.Errorf("unknown provider '%s'. Providers are %s, or plugins named %s<name> in PATH", name, strings.Join(Providers(), ", "), pluginPrefix)
+		}
+	}
+	return NewPluginClient(path), nil
+}
@@ -179,14 +179,16 @@ type apiResponse struct {
 	} `json:"build,omitempty"`
 	// workaround for deployments
 	// TODO: fix on platform
-	Message   string  `json:"message"`
-	Events    []event `json:"events,omitempty"`
-	Command   string  `json:"command,omitempty"`
-	IPAddress string  `json:"ip_address,omitempty"`
+	Message   string            `json:"message"`
+	Events    []event           `json:"events,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
 }
 
 type event struct {
 	Timestamp time.Time `json:"timestamp"`
-	Status    string    `json:"status"`
+	Status    Status    `json:"status"`
 	Code      int       `json:"code"`
+	Message   string    `json:"message,omitempty"`
 }
@@ -0,0 +1,234 @@
+package reco
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// SimulationReport is the result of a finished simulation.
+type SimulationReport struct {
+	ID       string        `json:"id"`
+	Command  string        `json:"command"`
+	Status   Status        `json:"status"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	// Failure describes why the simulation failed. It is empty
+	// if the simulation passed.
+	Failure string `json:"failure,omitempty"`
+	// Area is the estimated area of the simulated design, if reported.
+	Area *BuildReport `json:"area,omitempty"`
+	// Content is the report as generated by the simulation, if any.
+	// Content that is not JSON is a JSON string.
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
+// MarshalJSON encodes the report with its status in lower case and
+// its duration in seconds, as in the raw output of tables.
+func (r SimulationReport) MarshalJSON() ([]byte, error) {
+	type report SimulationReport
+	return json.Marshal(struct {
+		report
+		Status   string  `json:"status"`
+		Duration float64 `json:"duration"`
+	}{report(r), r.Status.lower(), r.Duration.Seconds()})
+}
+
+// Passed checks if the simulation passed.
+func (r SimulationReport) Passed() bool {
+	return r.Failure == ""
+}
+
+// TimedOut checks if the simulation exceeded its time limit.
+func (r SimulationReport) TimedOut() bool {
+	return r.Status.Is(StatusTimeout)
+}
+
+// Result returns "passed" or "failed".
+func (r SimulationReport) Result() string {
+	if r.Passed() {
+		return "passed"
+	}
+	return "failed"
+}
+
+// Table returns the report summary as a table.
+func (r SimulationReport) Table() printer.Table {
+	return SimulationTable([]SimulationReport{r})
+}
+
+// SimulationTable returns a summary table of simulation reports.
+func SimulationTable(reports []SimulationReport) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "id", Title: "simulation id"},
+			{Name: "command", Title: "command"},
+			{Name: "status", Title: "status"},
+			{Name: "duration", Title: "duration"},
+			{Name: "result", Title: "result"},
+			{Name: "failure", Title: "failure", Hidden: true},
+		},
+	}
+	for _, r := range reports {
+		result := printer.Colored{Value: r.Result(), Color: printer.Green}
+		if !r.Passed() {
+			result.Color = printer.Red
+		}
+		table.Body = append(table.Body, printer.Row{
+			r.ID,
+			r.Command,
+			printer.Colored{Value: r.Status.lower(), Color: statusColor(r.Status)},
+			r.Duration,
+			result,
+			r.Failure,
+		})
+	}
+	return table
+}
+
+// simulationResult is the outcome recorded in a simulation report.
+type simulationResult struct {
+	Passed *bool    `json:"passed"`
+	Error  string   `json:"error"`
+	Errors []string `json:"errors"`
+}
+
+// reportContent returns the report content as JSON. Content is
+// decoded as is first, as only some platforms escape it. Content that
+// is not JSON even unescaped is returned as a JSON string.
+func reportContent(content string) json.RawMessage {
+	for _, c := range []string{content, strings.Replace(content, "\\", "", -1)} {
+		var raw json.RawMessage
+		if err := json.Unmarshal([]byte(c), &raw); err == nil {
+			return raw
+		}
+	}
+	raw, _ := json.Marshal(content)
+	return raw
+}
+
+// newSimulationReport creates the report of a finished simulation
+// from its job and the report content, which may be empty. Content
+// without a simulation result does not change the outcome.
+func newSimulationReport(job jobInfo, content string) SimulationReport {
+	report := SimulationReport{
+		ID:       job.ID,
+		Command:  job.Command,
+		Status:   job.Status.upper(),
+		Started:  job.Time,
+		Duration: job.Duration,
+	}
+	if content != "" {
+		report.Content = reportContent(content)
+		var result simulationResult
+		// content that is not an object has no result.
+		json.Unmarshal(report.Content, &result)
+		var area BuildReport
+		if err := json.Unmarshal(report.Content, &area); err == nil && area.LUTs.Available > 0 {
+			report.Area = &area
+		}
+		switch {
+		case result.Error != "":
+			report.Failure = result.Error
+		case len(result.Errors) > 0:
+			report.Failure = strings.Join(result.Errors, "\n")
+		case result.Passed != nil && !*result.Passed:
+			report.Failure = "Simulation reported failure"
+		}
+	}
+
+	switch job.Status.upper() {
+	case StatusCompleted:
+	case StatusTimeout:
+		report.Failure = fmt.Sprintf("Simulation timed out (exit code %d)", ErrorCodeTimeout)
+		if job.Reason != "" {
+			report.Failure += ": " + job.Reason
+		}
+	default:
+		report.Failure = "Simulation " + job.Status.lower()
+		if job.Reason != "" {
+			report.Failure += ": " + job.Reason
+		}
+	}
+	return report
+}
+
+// junitTestSuites is the root of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes simulation reports as a JUnit XML test suite,
+// with a test case for each simulation.
+func WriteJUnit(w io.Writer, name string, reports []SimulationReport) error {
+	suite := junitTestSuite{Name: name, Tests: len(reports)}
+	var total time.Duration
+	for _, r := range reports {
+		if suite.Timestamp == "" && !r.Started.IsZero() {
+			suite.Timestamp = r.Started.UTC().Format("2006-01-02T15:04:05")
+		}
+		total += r.Duration
+		testCase := junitTestCase{
+			Name:      r.Command,
+			ClassName: "simulation." + r.ID,
+			Time:      junitSeconds(r.Duration),
+		}
+		if testCase.Name == "" {
+			testCase.Name = r.ID
+		}
+		if !r.Passed() {
+			suite.Failures++
+			failureType := r.Status.lower()
+			if r.TimedOut() {
+				failureType = "timeout"
+			}
+			message := strings.SplitN(r.Failure, "\n", 2)[0]
+			testCase.Failure = &junitFailure{Message: message, Type: failureType, Text: r.Failure}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,94 @@
+package reco
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTimedOutSimulation = `{
+	"id": "sim1",
+	"command": "test-addition",
+	"job": {"events": [
+		{"timestamp": "2017-01-01T10:00:00Z", "status": "QUEUED"},
+		{"timestamp": "2017-01-01T10:01:00Z", "status": "STARTED"},
+		{"timestamp": "2017-01-01T11:01:00Z", "status": "ERRORED", "code": 124, "message": "exceeded 1h time limit"}
+	]}
+}`
+
+func TestNewSimulationReport(t *testing.T) {
+	var timedOut jobInfo
+	if err := json.Unmarshal([]byte(testTimedOutSimulation), &timedOut); err != nil {
+		t.Fatal(err)
+	}
+	completed := jobInfo{ID: "sim2", Command: "test-addition", Status: "completed", Duration: time.Minute}
+
+	tests := []struct {
+		job     jobInfo
+		content string
+		passed  bool
+		failure string
+	}{
+		{completed, "", true, ""},
+		{completed, `{\"passed\":true}`, true, ""},
+		{completed, `{\"errors\":[\"1 != 2\"]}`, false, "1 != 2"},
+		{completed, `{"error":"expected \"a\"\nfound \"b\""}`, false, "expected \"a\"\nfound \"b\""},
+		{completed, `[1, 2]`, true, ""},
+		{completed, `all tests passed`, true, ""},
+		{jobInfo{Status: "errored", Reason: "build failed"}, "", false, "Simulation errored: build failed"},
+		{timedOut, "", false, "Simulation timed out (exit code 124): exceeded 1h time limit"},
+	}
+	for _, test := range tests {
+		report := newSimulationReport(test.job, test.content)
+		var content interface{}
+		if err := json.Unmarshal(report.Content, &content); test.content != "" && err != nil {
+			t.Errorf("%q: invalid content %s", test.content, report.Content)
+		}
+		if report.Passed() != test.passed || report.Failure != test.failure {
+			t.Errorf("%s %q: expected passed=%v failure %q, got passed=%v failure %q",
+				test.job.Status, test.content, test.passed, test.failure, report.Passed(), report.Failure)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var timedOut jobInfo
+	if err := json.Unmarshal([]byte(testTimedOutSimulation), &timedOut); err != nil {
+		t.Fatal(err)
+	}
+	failed := newSimulationReport(timedOut, "")
+	passed := newSimulationReport(jobInfo{ID: "sim2", Command: "test-sum", Status: "completed", Duration: 90 * time.Second}, "")
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "reco simulations", []SimulationReport{failed, passed}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		`<testsuite name="reco simulations" tests="2" failures="1" time="3750.000" timestamp="2017-01-01T10:00:00">`,
+		`<testcase name="test-addition" classname="simulation.sim1" time="3660.000">`,
+		`<failure message="Simulation timed out (exit code 124): exceeded 1h time limit" type="timeout">`,
+		`<testcase name="test-sum" classname="simulation.sim2" time="90.000"></testcase>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %s in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestSimulationReportJSON(t *testing.T) {
+	report := newSimulationReport(jobInfo{ID: "sim1", Status: "COMPLETED", Duration: 90 * time.Second}, "")
+	b, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["status"] != "completed" || decoded["duration"] != 90.0 || decoded["id"] != "sim1" {
+		t.Errorf("unexpected report JSON %s", b)
+	}
+}
@@ -0,0 +1,230 @@
+package reco
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SimulationSuiteFile is the default name of the file declaring a
+// project's simulation suite, in the source directory.
+const SimulationSuiteFile = "reco-sims.yml"
+
+// defaultSuiteConcurrency is the number of suite simulations run at
+// once if the suite does not set it.
+const defaultSuiteConcurrency = 4
+
+// SimulationSuite is a set of named simulations run together e.g.
+//
+//	concurrency: 2
+//	simulations:
+//	  - name: addition
+//	    command: test-addition
+//	    args: ["-n", "64"]
+//	  - name: overflow
+//	    command: test-overflow
+//	    expect: fail
+type SimulationSuite struct {
+	// Concurrency is the maximum number of simulations run at once.
+	Concurrency int               `yaml:"concurrency"`
+	Simulations []SuiteSimulation `yaml:"simulations"`
+}
+
+// SuiteSimulation is a simulation of a suite.
+type SuiteSimulation struct {
+	Name    string   `yaml:"name"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	// Expect is the expected outcome, "pass" (default) or "fail".
+	Expect string `yaml:"expect"`
+}
+
+// CommandLine returns the simulation command with its arguments.
+func (s SuiteSimulation) CommandLine() string {
+	return strings.Join(append([]string{s.Command}, s.Args...), " ")
+}
+
+// LoadSimulationSuite loads a simulation suite from file.
+func LoadSimulationSuite(file string) (SimulationSuite, error) {
+	var suite SimulationSuite
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return suite, err
+	}
+	if err := yaml.Unmarshal(b, &suite); err != nil {
+		return suite, fmt.Errorf("invalid suite file %s: %v", file, err)
+	}
+	if err := suite.validate(); err != nil {
+		return suite, fmt.Errorf("invalid suite file %s: %v", file, err)
+	}
+	return suite, nil
+}
+
+func (s *SimulationSuite) validate() error {
+	if len(s.Simulations) == 0 {
+		return fmt.Errorf("no simulations")
+	}
+	if s.Concurrency < 0 {
+		return fmt.Errorf("invalid concurrency %d", s.Concurrency)
+	}
+	names := make(map[string]bool)
+	for i := range s.Simulations {
+		sim := &s.Simulations[i]
+		if sim.Command == "" {
+			return fmt.Errorf("simulation %d has no command", i+1)
+		}
+		if sim.Name == "" {
+			sim.Name = sim.Command
+		}
+		if names[sim.Name] {
+			return fmt.Errorf("duplicate simulation name '%s'", sim.Name)
+		}
+		names[sim.Name] = true
+		switch strings.ToLower(sim.Expect) {
+		case "", "pass":
+			sim.Expect = "pass"
+		case "fail":
+			sim.Expect = "fail"
+		default:
+			return fmt.Errorf("simulation '%s' expects '%s'. Expected outcomes are pass or fail", sim.Name, sim.Expect)
+		}
+	}
+	return nil
+}
+
+// SuiteResult is the result of a suite simulation.
+type SuiteResult struct {
+	Simulation SuiteSimulation
+	Report     SimulationReport
+	// Err is set if the simulation could not be run.
+	Err error
+}
+
+// Passed checks if the simulation had its expected outcome.
+func (r SuiteResult) Passed() bool {
+	return r.Err == nil && r.Report.Passed() == (r.Simulation.Expect != "fail")
+}
+
+// Checked returns the simulation report named after the suite
+// simulation, failed if the simulation did not have its expected
+// outcome.
+func (r SuiteResult) Checked() SimulationReport {
+	report := r.Report
+	report.Command = r.Simulation.Name
+	switch {
+	case r.Err != nil:
+		report.Failure = r.Err.Error()
+	case r.Passed():
+		report.Failure = ""
+	case report.Passed():
+		report.Failure = "Simulation passed but was expected to fail"
+	}
+	return report
+}
+
+// SimulationSuiteRunner can run simulation suites.
+type SimulationSuiteRunner interface {
+	// RunSuite runs the simulations of a suite with the source in srcDir,
+	// writing their logs to w prefixed by simulation name.
+	RunSuite(srcDir string, suite SimulationSuite, vendor bool, w io.Writer) ([]SuiteResult, error)
+}
+
+// RunSuite archives the source once and runs the suite simulations in
+// parallel, up to the suite concurrency. The archive is uploaded for
+// each simulation, as the platform takes the source of each
+// simulation separately. Results are in suite order.
+func (t testJob) RunSuite(srcDir string, suite SimulationSuite, vendor bool, w io.Writer) ([]SuiteResult, error) {
+	logger.Info.Println("archiving")
+	srcArchive, err := archiveDir(srcDir, vendor)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info.Println("done")
+
+	concurrency := suite.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultSuiteConcurrency
+	}
+	results := make([]SuiteResult, len(suite.Simulations))
+	runParallel(len(suite.Simulations), concurrency, func(i int) {
+		sim := suite.Simulations[i]
+		results[i] = SuiteResult{Simulation: sim}
+		id, err := t.launch(sim.CommandLine(), srcArchive)
+		if err != nil {
+			results[i].Report.ID = id
+			results[i].Err = err
+			return
+		}
+		logger.Info.Printf("started %s. Simulation ID: %s", sim.Name, id)
+		logs := logger.NewPrefixWriter(w, "["+sim.Name+"] ")
+		results[i].Report, results[i].Err = t.follow(id, logs)
+		results[i].Report.ID = id
+		logs.Close()
+	})
+	return results, nil
+}
+
+// runParallel calls f for 0 to n-1 with up to concurrency calls at
+// once, and waits for all calls to return.
+func runParallel(n, concurrency int, f func(i int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// SuiteTable returns a summary table of suite results.
+func SuiteTable(results []SuiteResult) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "name", Title: "name"},
+			{Name: "id", Title: "simulation id"},
+			{Name: "status", Title: "status"},
+			{Name: "duration", Title: "duration"},
+			{Name: "expected", Title: "expected"},
+			{Name: "result", Title: "result"},
+			{Name: "failure", Title: "failure", Hidden: true},
+		},
+	}
+	for _, r := range results {
+		result := printer.Colored{Value: "pass", Color: printer.Green}
+		if !r.Passed() {
+			result = printer.Colored{Value: "fail", Color: printer.Red}
+		}
+		failure := r.Checked().Failure
+		var status interface{}
+		if r.Report.Status != "" {
+			status = printer.Colored{Value: r.Report.Status.lower(), Color: statusColor(r.Report.Status)}
+		}
+		var id interface{}
+		if r.Report.ID != "" {
+			id = r.Report.ID
+		}
+		table.Body = append(table.Body, printer.Row{
+			r.Simulation.Name,
+			id,
+			status,
+			r.Report.Duration,
+			r.Simulation.Expect,
+			result,
+			failure,
+		})
+	}
+	return table
+}
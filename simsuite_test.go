@@ -0,0 +1,158 @@
+package reco
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLoadSimulationSuite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, SimulationSuiteFile)
+	content := `
+concurrency: 2
+simulations:
+  - name: addition
+    command: test-addition
+    args: ["-n", "64"]
+  - command: test-overflow
+    expect: FAIL
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := LoadSimulationSuite(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite.Concurrency != 2 || len(suite.Simulations) != 2 {
+		t.Fatalf("unexpected suite %+v", suite)
+	}
+	if cmd := suite.Simulations[0].CommandLine(); cmd != "test-addition -n 64" {
+		t.Errorf("unexpected command line %q", cmd)
+	}
+	if sim := suite.Simulations[1]; sim.Name != "test-overflow" || sim.Expect != "fail" {
+		t.Errorf("unexpected simulation %+v", sim)
+	}
+
+	for _, invalid := range []string{
+		"simulations: []",
+		"simulations: [{name: a}]",
+		"simulations: [{command: a}, {command: a}]",
+		"simulations: [{command: a, expect: maybe}]",
+	} {
+		if err := ioutil.WriteFile(file, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSimulationSuite(file); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSuiteResultPassed(t *testing.T) {
+	pass := SuiteSimulation{Name: "a", Expect: "pass"}
+	fail := SuiteSimulation{Name: "b", Expect: "fail"}
+	passed := SimulationReport{Status: "completed"}
+	failed := SimulationReport{Status: "errored", Failure: "Simulation errored"}
+
+	tests := []struct {
+		result  SuiteResult
+		passed  bool
+		failure string
+	}{
+		{SuiteResult{Simulation: pass, Report: passed}, true, ""},
+		{SuiteResult{Simulation: pass, Report: failed}, false, "Simulation errored"},
+		{SuiteResult{Simulation: fail, Report: failed}, true, ""},
+		{SuiteResult{Simulation: fail, Report: passed}, false, "Simulation passed but was expected to fail"},
+		{SuiteResult{Simulation: pass, Err: errors.New("upload failed")}, false, "upload failed"},
+	}
+	for _, test := range tests {
+		if test.result.Passed() != test.passed {
+			t.Errorf("%+v: expected passed=%v", test.result, test.passed)
+		}
+		if checked := test.result.Checked(); checked.Failure != test.failure || checked.Command != test.result.Simulation.Name {
+			t.Errorf("%+v: unexpected checked report %+v", test.result, checked)
+		}
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning, calls := 0, 0, 0
+	block := make(chan struct{})
+	go func() {
+		// let calls pile up before releasing them one by one.
+		for i := 0; i < 10; i++ {
+			block <- struct{}{}
+		}
+	}()
+	runParallel(10, 3, func(i int) {
+		mu.Lock()
+		running++
+		calls++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		<-block
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if calls != 10 {
+		t.Errorf("expected 10 calls, got %d", calls)
+	}
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", maxRunning)
+	}
+}
+
+func TestLaunchUploadFailure(t *testing.T) {
+	var stopped bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/projects":
+			json.NewEncoder(w).Encode(M{"value": []M{}})
+		case r.Method == "POST" && r.URL.Path == "/simulations":
+			json.NewEncoder(w).Encode(M{"value": M{"id": "sim1"}})
+		case r.Method == "PUT" && r.URL.Path == "/simulations/sim1/input":
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Method == "POST" && r.URL.Path == "/simulations/sim1/events":
+			stopped = true
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	archive, err := ioutil.TempFile("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+	defer os.Remove(archive.Name())
+
+	job := testJob{&clientImpl{platformServer: server.URL, Username: "user", Token: "token", ProjectID: "project"}}
+	id, err := job.launch("test-addition", archive.Name())
+	if err == nil {
+		t.Error("failed upload did not fail")
+	}
+	if id != "sim1" {
+		t.Errorf("Expected simulation ID sim1, found %q", id)
+	}
+	if !stopped {
+		t.Error("simulation without its source was not stopped")
+	}
+}
@@ -1,24 +1,22 @@
 package reco
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
-	"time"
 
 	"github.com/ReconfigureIO/reco/logger"
 	"github.com/ReconfigureIO/reco/printer"
-	humanize "github.com/dustin/go-humanize"
 )
 
-var _ Job = &testJob{}
+var _ SimulationJob = &testJob{}
 
 // SimulationReporter can return simulation reports.
 type SimulationReporter interface {
-	// Report returns a formatted JSON simulation report.
-	Report(id string) (string, error)
+	// Report returns the report of a finished simulation.
+	Report(id string) (SimulationReport, error)
 }
 
 type testJob struct {
@@ -46,23 +44,29 @@ func (t testJob) prepareTest(command string) (string, error) {
 	return respJSON.Value.ID, err
 }
 
+// Start starts a simulation with positional args.
+//
+// Deprecated: use StartSimulation.
 func (p testJob) Start(args Args) (string, error) {
-	srcDir := String(args.At(0))
-	cmd := String(args.At(1))
-	cmdArgs := StringSlice(args.At(2))
+	return p.StartSimulation(simulationOptions(args))
+}
 
-	if len(cmdArgs) > 0 {
-		cmd += " " + strings.Join(cmdArgs, " ")
+func (p testJob) StartSimulation(opts SimulationOptions) (string, error) {
+	cmd := opts.Command
+	if len(opts.Args) > 0 {
+		cmd += " " + strings.Join(opts.Args, " ")
 	}
-	logger.Info.Println("preparing simulation")
-	id, err := p.prepareTest(cmd)
+	// archive first, so that missing dependencies leave no simulation
+	// without a source.
+	logger.Info.Println("archiving")
+	srcArchive, err := archiveDir(opts.SourceDir, opts.Vendor)
 	if err != nil {
 		return "", err
 	}
 	logger.Info.Println("done")
 
-	logger.Info.Println("archiving")
-	srcArchive, err := archiveDir(srcDir)
+	logger.Info.Println("preparing simulation")
+	id, err := p.prepareTest(cmd)
 	if err != nil {
 		return "", err
 	}
@@ -74,52 +78,72 @@ func (p testJob) Start(args Args) (string, error) {
 	}
 	logger.Info.Println("done")
 
-	logger.Info.Println("running simulation")
-	logger.Info.Println()
-	p.waitAndLog("simulation", id)
-
+	logger.Info.Println("done. Simulation ID: ", id)
+	switch opts.Wait {
+	case WaitNone:
+		logger.Info.Println(`you can run "reco sim log `, id, `" to manually stream logs`)
+	case WaitStatus:
+		logger.Info.Println("waiting for simulation to finish")
+		if _, err := p.waitForJob(JobTypeSimulation, id, jobInfo.IsCompleted); err != nil {
+			return id, err
+		}
+	default:
+		logger.Info.Println("running simulation")
+		logger.Info.Println()
+		p.waitAndLog("simulation", id)
+	}
 	return id, nil
 }
 
-func (b testJob) List(filter M) (printer.Table, error) {
-	var table printer.Table
-	allProjects := filter.Bool("all")
-	simulations, err := b.clientImpl.listTests(filter)
+// launch starts a simulation of command with an archived source.
+// If the upload fails, the simulation is stopped and its ID is
+// returned with the error.
+func (t testJob) launch(command, srcArchive string) (string, error) {
+	id, err := t.prepareTest(command)
 	if err != nil {
-		return table, err
+		return "", err
 	}
-	var body [][]string
-	for _, sim := range simulations {
-		simTime := "-"
-		if !sim.Time.IsZero() {
-			simTime = humanize.Time(sim.Time)
-		}
-
-		row := []string{
-			sim.ID,
-			sim.Status,
-			simTime,
-			timeRounder(sim.Duration).Nearest(time.Second),
-		}
-		if allProjects {
-			row = append(row, sim.Project)
+	if err := t.uploadJob(JobTypeSimulation, id, srcArchive); err != nil {
+		// the simulation would wait for its source forever.
+		if stopErr := t.stopJob(JobTypeSimulation, id); stopErr != nil {
+			return id, fmt.Errorf("uploading the source failed: %v. Stopping the simulation also failed: %v", err, stopErr)
 		}
-
-		body = append(body, row)
+		return id, fmt.Errorf("uploading the source failed, so the simulation was stopped: %v", err)
 	}
+	return id, nil
+}
 
-	table = printer.Table{
-		Header: []string{"simulation id", "status", "started", "duration"},
-		Body:   body,
+// follow streams the logs of a simulation to w once it starts,
+// waits for it to finish and returns its report.
+func (t testJob) follow(id string, w io.Writer) (SimulationReport, error) {
+	if _, err := t.waitForJob(JobTypeSimulation, id, jobInfo.IsStarted); err != nil {
+		return SimulationReport{}, err
+	}
+	if err := t.streamLog(JobTypeSimulation, id, w); err != nil {
+		return SimulationReport{}, err
 	}
-	if allProjects {
-		table.Header = append(table.Header, "project")
+	if _, err := t.waitForJob(JobTypeSimulation, id, jobInfo.IsCompleted); err != nil {
+		return SimulationReport{}, err
 	}
+	return t.Report(id)
+}
 
-	return table, nil
+func (b testJob) List(filter M) (printer.Table, error) {
+	allProjects := filter.Bool("all")
+	simulations, err := b.clientImpl.listTests(filter)
+	if err != nil {
+		return printer.Table{}, err
+	}
+	return jobTable(simulations, allProjects,
+		colID.titled("simulation id"),
+		colStatus,
+		colStarted,
+		colDuration,
+		colCommand.hidden(),
+	), nil
 }
 
-func (t testJob) Status(id string) string {
+func (t testJob) Status(id string) Status {
 	return t.clientImpl.getStatus("simulation", id)
 }
 
@@ -131,44 +155,42 @@ func (t testJob) Log(id string, writer io.Writer) error {
 	return t.clientImpl.logJob("simulation", id)
 }
 
-type simulationReport struct {
-	Report string `json:"report"`
-}
+func (t testJob) Report(id string) (SimulationReport, error) {
+	job, err := t.getJob(JobTypeSimulation, id)
+	if err != nil {
+		return SimulationReport{}, err
+	}
+	if !job.IsCompleted() {
+		return SimulationReport{}, fmt.Errorf("Simulation has not finished. Status: %s", job.Status)
+	}
 
-func (t testJob) Report(id string) (string, error) {
-	var req = t.apiRequest(endpoints.simulations.Report())
-	req.param("id", id)
+	req, err := t.jobRequest(JobTypeSimulation, opReport, id)
+	if err != nil {
+		return SimulationReport{}, err
+	}
 	resp, err := req.Do("GET", nil)
+	if err == ErrNotFound {
+		// failed simulations may not generate a report.
+		return newSimulationReport(job, ""), nil
+	}
 	if err != nil {
-		return "", err
+		return SimulationReport{}, err
 	}
+	var content string
 	switch resp.StatusCode {
-	case 404:
-		return "", errors.New("Report not found")
 	case 204:
-		return "", errors.New("No report generated. Reports are only generated for COMPLETED simulations")
 	case 200:
-		break
+		var apiResp struct {
+			Value struct {
+				Report string `json:"report"`
+			} `json:"value"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+			return SimulationReport{}, err
+		}
+		content = apiResp.Value.Report
 	default:
-		return "", errors.New("Unknown error occured")
+		return SimulationReport{}, errors.New("Unknown error occured")
 	}
-
-	var apiResp struct {
-		Value struct {
-			Report string `json:"report"`
-		} `json:"value"`
-	}
-	err = json.NewDecoder(resp.Body).Decode(&apiResp)
-	if err != nil {
-		return "", err
-	}
-
-	validJSONReport := strings.Replace(apiResp.Value.Report, "\\", "", -1)
-	var prettyJSONReport bytes.Buffer
-	err = json.Indent(&prettyJSONReport, []byte(validJSONReport), "", "\t")
-	if err != nil {
-		return "", err
-	}
-
-	return prettyJSONReport.String(), nil
+	return newSimulationReport(job, content), nil
 }
@@ -0,0 +1,175 @@
+package reco
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// Status is the status of a job. Statuses are upper case, but
+// methods of Status ignore case, as statuses of older servers and
+// older local jobs may be lower case.
+//
+// Statuses reported by the platform, plugins and local jobs are not
+// checked, so a Status may be none of Statuses if the platform adds
+// one. Such statuses are displayed like others, are neither final nor
+// started, and are assumed to be able to reach any status. Only
+// statuses given by users, with ParseStatus, are rejected if unknown.
+type Status string
+
+const (
+	// StatusSubmitted is submitted job state.
+	StatusSubmitted Status = "SUBMITTED"
+	// StatusQueued is queued job state.
+	StatusQueued Status = "QUEUED"
+	// StatusCreatingImage is creating image job state.
+	StatusCreatingImage Status = "CREATING_IMAGE"
+	// StatusStarted is started job state.
+	StatusStarted Status = "STARTED"
+	// StatusTerminating is terminating job state.
+	StatusTerminating Status = "TERMINATING"
+	// StatusTerminated is terminated job state.
+	StatusTerminated Status = "TERMINATED"
+	// StatusCompleted is completed job state.
+	StatusCompleted Status = "COMPLETED"
+	// StatusErrored is errored job state.
+	StatusErrored Status = "ERRORED"
+	// StatusTimeout is the state of jobs ended by an error event with
+	// code ErrorCodeTimeout. It isn't reported by the platform.
+	StatusTimeout Status = "TIMED-OUT"
+
+	// ErrorCodeTimeout is the code of error events of timed out jobs.
+	ErrorCodeTimeout = 124
+)
+
+// Statuses are all job statuses.
+var Statuses = []Status{
+	StatusSubmitted,
+	StatusQueued,
+	StatusCreatingImage,
+	StatusStarted,
+	StatusTerminating,
+	StatusTerminated,
+	StatusCompleted,
+	StatusErrored,
+	StatusTimeout,
+}
+
+// statusTransitions are the statuses a job can go to from each
+// status. Final statuses have none.
+var statusTransitions = map[Status][]Status{
+	StatusSubmitted:     {StatusQueued, StatusTerminating, StatusTerminated, StatusErrored},
+	StatusQueued:        {StatusCreatingImage, StatusStarted, StatusTerminating, StatusTerminated, StatusErrored},
+	StatusCreatingImage: {StatusStarted, StatusTerminating, StatusTerminated, StatusErrored},
+	StatusStarted:       {StatusCompleted, StatusTerminating, StatusTerminated, StatusErrored, StatusTimeout},
+	StatusTerminating:   {StatusTerminated, StatusErrored},
+}
+
+// ParseStatus parses a status, in any case.
+func ParseStatus(s string) (Status, error) {
+	status := Status(strings.ToUpper(s))
+	if !status.known() {
+		names := make([]string, len(Statuses))
+		for i, s := range Statuses {
+			names[i] = s.lower()
+		}
+		return "", fmt.Errorf("invalid status '%s'. Statuses are %s", s, strings.Join(names, ", "))
+	}
+	return status, nil
+}
+
+// statusFilter returns the status of the status filter of filters,
+// or an empty status if there is none.
+func statusFilter(filters M) (Status, error) {
+	switch status := filters["status"].(type) {
+	case Status:
+		return status, nil
+	case string:
+		if status != "" {
+			return ParseStatus(status)
+		}
+	}
+	return "", nil
+}
+
+// upper returns the status in upper case.
+func (s Status) upper() Status {
+	return Status(strings.ToUpper(string(s)))
+}
+
+// lower returns the status in lower case, as statuses are displayed.
+func (s Status) lower() string {
+	return strings.ToLower(string(s))
+}
+
+func (s Status) known() bool {
+	for _, status := range Statuses {
+		if s.upper() == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Is checks if s is status, ignoring case.
+func (s Status) Is(status Status) bool {
+	return s.upper() == status.upper()
+}
+
+// IsFinal checks if the status is a final status.
+func (s Status) IsFinal() bool {
+	switch s.upper() {
+	case StatusCompleted, StatusErrored, StatusTerminated, StatusTimeout:
+		return true
+	}
+	return false
+}
+
+// IsStarted checks if a job in the status has started.
+func (s Status) IsStarted() bool {
+	switch s.upper() {
+	case StatusCompleted, StatusErrored, StatusTerminated, StatusTerminating, StatusStarted, StatusCreatingImage, StatusTimeout:
+		return true
+	}
+	return false
+}
+
+// CanReach checks if a job in status s can later be in status to,
+// directly or through other statuses, as status changes may be missed
+// between polls. Unknown statuses are assumed to be reachable.
+func (s Status) CanReach(to Status) bool {
+	from, to := s.upper(), to.upper()
+	if !from.known() || !to.known() {
+		return true
+	}
+	seen := map[Status]bool{from: true}
+	next := []Status{from}
+	for len(next) > 0 {
+		status := next[0]
+		next = next[1:]
+		for _, n := range statusTransitions[status] {
+			if n == to {
+				return true
+			}
+			if !seen[n] {
+				seen[n] = true
+				next = append(next, n)
+			}
+		}
+	}
+	return false
+}
+
+// statusColor returns the color status is displayed in.
+func statusColor(status Status) printer.Color {
+	switch status.upper() {
+	case StatusCompleted:
+		return printer.Green
+	case StatusErrored, StatusTimeout:
+		return printer.Red
+	case StatusQueued:
+		return printer.Yellow
+	}
+	return printer.NoColor
+}
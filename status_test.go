@@ -0,0 +1,62 @@
+package reco
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range []string{"completed", "COMPLETED", "Timed-Out", "creating_image"} {
+		if _, err := ParseStatus(s); err != nil {
+			t.Errorf("ParseStatus(%s) failed: %v", s, err)
+		}
+	}
+	if status, _ := ParseStatus("queued"); status != StatusQueued {
+		t.Errorf("Expected %s, found %s", StatusQueued, status)
+	}
+	for _, s := range []string{"", "done", "WAITING"} {
+		if _, err := ParseStatus(s); err == nil {
+			t.Errorf("ParseStatus(%s) did not fail", s)
+		}
+	}
+}
+
+func TestCanReach(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		expected bool
+	}{
+		{StatusSubmitted, StatusQueued, true},
+		{StatusSubmitted, StatusStarted, true},
+		{StatusSubmitted, StatusCompleted, true},
+		{StatusQueued, StatusTimeout, true},
+		{"started", StatusCompleted, true},
+		{StatusStarted, StatusQueued, false},
+		{StatusCompleted, StatusStarted, false},
+		{StatusTerminating, StatusStarted, false},
+		{StatusTerminating, StatusCompleted, false},
+		{StatusErrored, StatusErrored, false},
+		{"UNKNOWN", StatusStarted, true},
+	}
+	for _, test := range tests {
+		if reached := test.from.CanReach(test.to); reached != test.expected {
+			t.Errorf("%s.CanReach(%s) = %v, expected %v", test.from, test.to, reached, test.expected)
+		}
+	}
+	for _, status := range Statuses {
+		if status.IsFinal() != (len(statusTransitions[status]) == 0) {
+			t.Errorf("%s is final but has transitions, or is not final and has none", status)
+		}
+	}
+}
+
+func TestStatusFilter(t *testing.T) {
+	for _, filter := range []M{{"status": "completed"}, {"status": StatusCompleted}} {
+		if status, err := statusFilter(filter); err != nil || status != StatusCompleted {
+			t.Errorf("statusFilter(%v) = %s, %v", filter, status, err)
+		}
+	}
+	if status, err := statusFilter(M{}); err != nil || status != "" {
+		t.Errorf("Expected no status filter, found %s, %v", status, err)
+	}
+	if _, err := statusFilter(M{"status": "done"}); err == nil {
+		t.Error("invalid status filter did not fail")
+	}
+}
@@ -0,0 +1,199 @@
+package reco
+
+import (
+	"time"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// stopConcurrency is the number of stop requests sent at once.
+const stopConcurrency = 8
+
+// BulkStopper stops several jobs at once.
+type BulkStopper interface {
+	// StopTargets lists the jobs to stop. filter may have the keys
+	// "ids" ([]string), "status" (string) and "older_than"
+	// (time.Duration). Without ids, the unfinished jobs of the
+	// project are matched. Finished jobs are never matched.
+	StopTargets(filter M) (printer.Table, error)
+	// StopJobs stops jobs concurrently, returning the result of each.
+	StopJobs(ids []string) []StopResult
+}
+
+// StopResult is the result of stopping a job.
+type StopResult struct {
+	ID  string
+	Err error
+}
+
+// StopResultTable returns a table of stop results.
+func StopResultTable(results []StopResult) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "id", Title: "id"},
+			{Name: "result", Title: "result"},
+		},
+	}
+	for _, result := range results {
+		value := printer.Colored{Value: "stopped", Color: printer.Green}
+		if result.Err != nil {
+			value = printer.Colored{Value: result.Err.Error(), Color: printer.Red}
+		}
+		table.Body = append(table.Body, printer.Row{result.ID, value})
+	}
+	return table
+}
+
+// matchStopFilter checks if job matches the status and age of filter.
+func matchStopFilter(job jobInfo, filter M, now time.Time) bool {
+	if status, _ := statusFilter(filter); status != "" && !job.Status.Is(status) {
+		return false
+	}
+	if age, ok := filter["older_than"].(time.Duration); ok && age > 0 && now.Sub(job.Time) < age {
+		return false
+	}
+	return true
+}
+
+// stopTargets returns the jobs of jobType matching filter.
+func (p *clientImpl) stopTargets(jobType string, filter M) ([]jobInfo, error) {
+	if _, err := statusFilter(filter); err != nil {
+		return nil, err
+	}
+	var jobs []jobInfo
+	if ids, _ := filter["ids"].([]string); len(ids) > 0 {
+		for _, id := range ids {
+			id, err := p.resolveID(jobType, id)
+			if err != nil {
+				return nil, err
+			}
+			job, err := p.getJob(jobType, id)
+			if err != nil {
+				return nil, err
+			}
+			if job.IsCompleted() {
+				skipFinished(job)
+				continue
+			}
+			jobs = append(jobs, job)
+		}
+	} else {
+		all, err := p.listJobs(jobType, M{})
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range all {
+			if !job.IsCompleted() {
+				jobs = append(jobs, job)
+			}
+		}
+	}
+	return matchStopTargets(jobs, filter), nil
+}
+
+// skipFinished reports a job given to stop that has already finished.
+func skipFinished(job jobInfo) {
+	logger.Info.Printf("%s has already finished. Status: %s", job.ID, job.Status.lower())
+}
+
+// matchStopTargets returns the jobs matching the status and age of filter.
+func matchStopTargets(jobs []jobInfo, filter M) []jobInfo {
+	now := time.Now()
+	var targets []jobInfo
+	for _, job := range jobs {
+		if matchStopFilter(job, filter, now) {
+			targets = append(targets, job)
+		}
+	}
+	return targets
+}
+
+// stopJobs stops jobs of jobType concurrently.
+func (p *clientImpl) stopJobs(jobType string, ids []string) []StopResult {
+	results := make([]StopResult, len(ids))
+	runParallel(len(ids), stopConcurrency, func(i int) {
+		results[i] = StopResult{ID: ids[i], Err: p.stopJob(jobType, ids[i])}
+	})
+	return results
+}
+
+func (b buildJob) StopTargets(filter M) (printer.Table, error) {
+	builds, err := b.stopTargets(JobTypeBuild, filter)
+	if err != nil {
+		return printer.Table{}, err
+	}
+	return jobTable(builds, false, colID.titled("build id"), colStatus, colStarted, colMessage), nil
+}
+
+func (b buildJob) StopJobs(ids []string) []StopResult {
+	return b.stopJobs(JobTypeBuild, ids)
+}
+
+func (t testJob) StopTargets(filter M) (printer.Table, error) {
+	simulations, err := t.stopTargets(JobTypeSimulation, filter)
+	if err != nil {
+		return printer.Table{}, err
+	}
+	return jobTable(simulations, false, colID.titled("simulation id"), colStatus, colStarted, colCommand), nil
+}
+
+func (t testJob) StopJobs(ids []string) []StopResult {
+	return t.stopJobs(JobTypeSimulation, ids)
+}
+
+func (p deploymentJob) StopTargets(filter M) (printer.Table, error) {
+	deployments, err := p.stopTargets(JobTypeDeployment, filter)
+	if err != nil {
+		return printer.Table{}, err
+	}
+	return jobTable(deployments, false, colID.titled("deployment id"), colStatus, colStarted, colBuild, colCommand), nil
+}
+
+func (p deploymentJob) StopJobs(ids []string) []StopResult {
+	return p.stopJobs(JobTypeDeployment, ids)
+}
+
+func (l localSimulation) StopTargets(filter M) (printer.Table, error) {
+	if _, err := statusFilter(filter); err != nil {
+		return printer.Table{}, err
+	}
+	var simulations []jobInfo
+	if ids, _ := filter["ids"].([]string); len(ids) > 0 {
+		for _, id := range ids {
+			id, err := l.ResolveID(id)
+			if err != nil {
+				return printer.Table{}, err
+			}
+			job, err := l.store.load(id)
+			if err != nil {
+				return printer.Table{}, err
+			}
+			if job.Status.IsFinal() {
+				skipFinished(job.info())
+				continue
+			}
+			simulations = append(simulations, job.info())
+		}
+	} else {
+		jobs, err := l.store.all()
+		if err != nil {
+			return printer.Table{}, err
+		}
+		for _, job := range jobs {
+			if !job.Status.IsFinal() {
+				simulations = append(simulations, job.info())
+			}
+		}
+	}
+	simulations = matchStopTargets(simulations, filter)
+	return jobTable(simulations, false, colID.titled("simulation id"), colStatus, colStarted, colCommand), nil
+}
+
+func (l localSimulation) StopJobs(ids []string) []StopResult {
+	results := make([]StopResult, len(ids))
+	runParallel(len(ids), stopConcurrency, func(i int) {
+		results[i] = StopResult{ID: ids[i], Err: l.Stop(ids[i])}
+	})
+	return results
+}
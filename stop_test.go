@@ -0,0 +1,107 @@
+package reco
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+func TestMatchStopFilter(t *testing.T) {
+	now := time.Now()
+	job := jobInfo{ID: "1", Status: "started", Time: now.Add(-3 * time.Hour)}
+	tests := []struct {
+		filter M
+		match  bool
+	}{
+		{M{}, true},
+		{M{"status": "STARTED"}, true},
+		{M{"status": "queued"}, false},
+		{M{"older_than": 2 * time.Hour}, true},
+		{M{"older_than": 4 * time.Hour}, false},
+		{M{"status": "started", "older_than": time.Duration(0)}, true},
+	}
+	for i, test := range tests {
+		if match := matchStopFilter(job, test.filter, now); match != test.match {
+			t.Errorf("test %d: expected %v, got %v", i, test.match, match)
+		}
+	}
+}
+
+func TestStopResultTable(t *testing.T) {
+	table := StopResultTable([]StopResult{
+		{ID: "1"},
+		{ID: "2", Err: errors.New("Not found")},
+	})
+	if len(table.Body) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(table.Body))
+	}
+	if v := table.Value(0, "result"); v.(printer.Colored).Value != "stopped" {
+		t.Errorf("unexpected result %v", v)
+	}
+	if v := table.Value(1, "result"); v.(printer.Colored).Value != "Not found" {
+		t.Errorf("unexpected result %v", v)
+	}
+}
+
+func TestLocalBulkStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sim := localSimulation{&localClient{store: localStore{dir: dir}}}
+	now := time.Now()
+	jobs := []localJob{
+		{ID: "a1", Status: StatusStarted, Started: now.Add(-3 * time.Hour)},
+		{ID: "b2", Status: StatusSubmitted, Started: now},
+		{ID: "c3", Status: StatusCompleted, Started: now.Add(-3 * time.Hour)},
+	}
+	for _, job := range jobs {
+		if err := sim.store.save(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	targets, err := sim.StopTargets(M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets.Body) != 2 {
+		t.Errorf("Expected the 2 unfinished simulations, found %d", len(targets.Body))
+	}
+	targets, err = sim.StopTargets(M{"older_than": time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets.Body) != 1 || targets.Value(0, "id") != "a1" {
+		t.Errorf("Expected simulation a1, found %v", targets.Body)
+	}
+	targets, err = sim.StopTargets(M{"ids": []string{"b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets.Body) != 1 || targets.Value(0, "id") != "b2" {
+		t.Errorf("Expected simulation b2, found %v", targets.Body)
+	}
+	targets, err = sim.StopTargets(M{"ids": []string{"c3", "a1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets.Body) != 1 || targets.Value(0, "id") != "a1" {
+		t.Errorf("Expected only unfinished simulation a1, found %v", targets.Body)
+	}
+
+	results := sim.StopJobs([]string{"a1", "b2"})
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("stopping %s: %v", result.ID, result.Err)
+		}
+		if !sim.store.stopRequested(result.ID) {
+			t.Errorf("stop of %s not requested", result.ID)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package reco
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+// SweepParam is a simulation parameter and the values to sweep it over.
+type SweepParam struct {
+	Name   string
+	Values []string
+}
+
+// sweepColumns are the columns of sweep tables besides parameters and
+// the metric, which parameters cannot be named after.
+var sweepColumns = []string{"id", "status", "duration", "error"}
+
+// ParseSweepParam parses a parameter of the form name=v1,v2,v3.
+func ParseSweepParam(s string) (SweepParam, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return SweepParam{}, fmt.Errorf("invalid parameter '%s'. Parameters are of the form name=value1,value2", s)
+	}
+	param := SweepParam{Name: strings.TrimSpace(parts[0])}
+	if inSlice(sweepColumns, param.Name) {
+		return param, fmt.Errorf("invalid parameter name '%s'. Parameters cannot be named %s", param.Name, strings.Join(sweepColumns, ", "))
+	}
+	for _, v := range strings.Split(parts[1], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			param.Values = append(param.Values, v)
+		}
+	}
+	if len(param.Values) == 0 {
+		return param, fmt.Errorf("parameter '%s' has no values", param.Name)
+	}
+	return param, nil
+}
+
+// Sweep is a simulation command run with every combination of
+// parameter values. Parameters referenced in args as {name} are
+// substituted, others are appended to args as -name=value.
+type Sweep struct {
+	Command string
+	Args    []string
+	Params  []SweepParam
+	// Metric is extracted from the last match in each simulation log,
+	// the first subexpression if any or else the whole match.
+	Metric *regexp.Regexp
+	// Concurrency is the maximum number of simulations run at once.
+	Concurrency int
+}
+
+// CheckColumns checks the parameter names are unique and differ from
+// the metric column name, so every column of the sweep table is unique.
+func (s Sweep) CheckColumns(metric string) error {
+	names := make(map[string]bool)
+	for _, param := range s.Params {
+		if names[param.Name] {
+			return fmt.Errorf("parameter '%s' is repeated", param.Name)
+		}
+		if param.Name == metric {
+			return fmt.Errorf("parameter '%s' has the name of the metric column. Use --metric-name to rename it", param.Name)
+		}
+		names[param.Name] = true
+	}
+	if inSlice(sweepColumns, metric) {
+		return fmt.Errorf("invalid metric name '%s'. The metric cannot be named %s", metric, strings.Join(sweepColumns, ", "))
+	}
+	return nil
+}
+
+// Combinations returns every combination of parameter values, with
+// values in parameter order.
+func (s Sweep) Combinations() [][]string {
+	combinations := [][]string{nil}
+	for _, param := range s.Params {
+		var next [][]string
+		for _, combination := range combinations {
+			for _, v := range param.Values {
+				c := append(append([]string{}, combination...), v)
+				next = append(next, c)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// CommandLine returns the command line for a combination of values.
+func (s Sweep) CommandLine(values []string) string {
+	args := make([]string, len(s.Args))
+	used := make(map[string]bool)
+	for i, arg := range s.Args {
+		for j, param := range s.Params {
+			placeholder := "{" + param.Name + "}"
+			if strings.Contains(arg, placeholder) {
+				arg = strings.Replace(arg, placeholder, values[j], -1)
+				used[param.Name] = true
+			}
+		}
+		args[i] = arg
+	}
+	for j, param := range s.Params {
+		if !used[param.Name] {
+			args = append(args, "-"+param.Name+"="+values[j])
+		}
+	}
+	return strings.Join(append([]string{s.Command}, args...), " ")
+}
+
+// metric returns the metric extracted from log, or nil if there is none.
+func (s Sweep) metric(log []byte) interface{} {
+	if s.Metric == nil {
+		return nil
+	}
+	matches := s.Metric.FindAllSubmatch(log, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	match := matches[len(matches)-1]
+	value := string(match[0])
+	if len(match) > 1 {
+		value = string(match[1])
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f
+	}
+	return value
+}
+
+// SweepResult is the result of a simulation of a sweep.
+type SweepResult struct {
+	Values []string
+	Report SimulationReport
+	// Metric is the metric extracted from the log, if found.
+	Metric interface{}
+	// Err is set if the simulation could not be run.
+	Err error
+}
+
+// SimulationSweeper can run parameter sweeps.
+type SimulationSweeper interface {
+	// Sweep runs the simulations of a sweep with the source in srcDir.
+	// If w is not nil, simulation logs are written to it prefixed by
+	// parameter values.
+	Sweep(srcDir string, sweep Sweep, vendor bool, w io.Writer) ([]SweepResult, error)
+}
+
+// Sweep archives the source once and runs a simulation for each
+// combination of parameter values in parallel, up to the sweep
+// concurrency. Results are in combination order.
+func (t testJob) Sweep(srcDir string, sweep Sweep, vendor bool, w io.Writer) ([]SweepResult, error) {
+	if w == nil {
+		w = ioutil.Discard
+	}
+	logger.Info.Println("archiving")
+	srcArchive, err := archiveDir(srcDir, vendor)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info.Println("done")
+
+	concurrency := sweep.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultSuiteConcurrency
+	}
+	combinations := sweep.Combinations()
+	results := make([]SweepResult, len(combinations))
+	runParallel(len(combinations), concurrency, func(i int) {
+		values := combinations[i]
+		results[i] = SweepResult{Values: values}
+		command := sweep.CommandLine(values)
+		id, err := t.launch(command, srcArchive)
+		if err != nil {
+			results[i].Err = err
+			return
+		}
+		logger.Info.Printf("started %s. Simulation ID: %s", command, id)
+		var log bytes.Buffer
+		logs := logger.NewPrefixWriter(w, "["+strings.Join(values, ",")+"] ")
+		results[i].Report, results[i].Err = t.follow(id, io.MultiWriter(&log, logs))
+		results[i].Report.ID = id
+		logs.Close()
+		results[i].Metric = sweep.metric(log.Bytes())
+	})
+	return results, nil
+}
+
+// SweepTable returns a table of sweep results, with a column for each
+// parameter and for the metric, named metric.
+func SweepTable(sweep Sweep, results []SweepResult, metric string) printer.Table {
+	var table printer.Table
+	for _, param := range sweep.Params {
+		table.Header = append(table.Header, printer.Column{Name: param.Name, Title: param.Name})
+	}
+	table.Header = append(table.Header,
+		printer.Column{Name: "id", Title: "simulation id"},
+		printer.Column{Name: "status", Title: "status"},
+		printer.Column{Name: "duration", Title: "duration"},
+		printer.Column{Name: metric, Title: metric, Hidden: sweep.Metric == nil},
+		printer.Column{Name: "error", Title: "error", Hidden: true},
+	)
+	for _, r := range results {
+		var row printer.Row
+		for _, v := range r.Values {
+			row = append(row, v)
+		}
+		var id, status, errMessage interface{}
+		if r.Report.ID != "" {
+			id = r.Report.ID
+		}
+		if r.Report.Status != "" {
+			status = printer.Colored{Value: r.Report.Status.lower(), Color: statusColor(r.Report.Status)}
+		}
+		if r.Err != nil {
+			errMessage = r.Err.Error()
+		}
+		row = append(row, id, status, r.Report.Duration, r.Metric, errMessage)
+		table.Body = append(table.Body, row)
+	}
+	return table
+}
@@ -0,0 +1,74 @@
+package reco
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseSweepParam(t *testing.T) {
+	param, err := ParseSweepParam("size=64, 128,256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if param.Name != "size" || !reflect.DeepEqual(param.Values, []string{"64", "128", "256"}) {
+		t.Errorf("unexpected param %+v", param)
+	}
+	for _, invalid := range []string{"size", "=64", "size=", "size=,", "id=1", "status=a,b"} {
+		if _, err := ParseSweepParam(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestSweepCombinations(t *testing.T) {
+	sweep := Sweep{
+		Command: "test-kernel",
+		Args:    []string{"-n", "{size}"},
+		Params: []SweepParam{
+			{Name: "size", Values: []string{"64", "128", "256"}},
+			{Name: "mode", Values: []string{"a", "b"}},
+		},
+	}
+	combinations := sweep.Combinations()
+	if len(combinations) != 6 {
+		t.Fatalf("expected 6 combinations, got %v", combinations)
+	}
+	if !reflect.DeepEqual(combinations[0], []string{"64", "a"}) || !reflect.DeepEqual(combinations[5], []string{"256", "b"}) {
+		t.Errorf("unexpected combinations %v", combinations)
+	}
+	if cmd := sweep.CommandLine(combinations[3]); cmd != "test-kernel -n 128 -mode=b" {
+		t.Errorf("unexpected command line %q", cmd)
+	}
+}
+
+func TestSweepMetric(t *testing.T) {
+	sweep := Sweep{Metric: regexp.MustCompile(`cycles: (\d+)`)}
+	log := []byte("warmup cycles: 10\nrun cycles: 4096\ndone\n")
+	if metric := sweep.metric(log); metric != 4096.0 {
+		t.Errorf("expected 4096, got %v", metric)
+	}
+	sweep.Metric = regexp.MustCompile(`result \w+`)
+	if metric := sweep.metric([]byte("result ok")); metric != "result ok" {
+		t.Errorf("expected whole match, got %v", metric)
+	}
+	if metric := sweep.metric([]byte("nothing")); metric != nil {
+		t.Errorf("expected no metric, got %v", metric)
+	}
+}
+
+func TestSweepCheckColumns(t *testing.T) {
+	sweep := Sweep{Params: []SweepParam{{Name: "size"}, {Name: "depth"}}}
+	if err := sweep.CheckColumns("metric"); err != nil {
+		t.Error(err)
+	}
+	for _, metric := range []string{"size", "error"} {
+		if err := sweep.CheckColumns(metric); err == nil {
+			t.Errorf("metric %s did not fail", metric)
+		}
+	}
+	sweep.Params = append(sweep.Params, SweepParam{Name: "size"})
+	if err := sweep.CheckColumns("metric"); err == nil {
+		t.Error("repeated parameter did not fail")
+	}
+}
@@ -0,0 +1,193 @@
+package reco
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ReconfigureIO/reco/logger"
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+const (
+	// deadlinesDir stores deployment deadlines in the global config
+	// directory, one file per deployment, so reaping from any directory
+	// and concurrent reco runs are safe.
+	deadlinesDir = "deadlines"
+	// reapLockFile is held in the deadlines directory while reaping, so
+	// overlapping reaps do not stop the same deployments.
+	reapLockFile = ".reap.lock"
+	// staleReapLock is the age of locks assumed left by a reap that
+	// did not finish.
+	staleReapLock = time.Hour
+)
+
+// DeploymentReaper stops deployments past their time-to-live.
+type DeploymentReaper interface {
+	// Reap stops started deployments past their deadline. With dryRun,
+	// they are only listed.
+	Reap(dryRun bool) ([]ReapResult, error)
+}
+
+var _ DeploymentReaper = deploymentJob{}
+
+// deploymentDeadline is the time a deployment should be stopped by.
+type deploymentDeadline struct {
+	ID       string    `json:"id"`
+	Deadline time.Time `json:"deadline"`
+}
+
+// ReapResult is a deployment past its deadline.
+type ReapResult struct {
+	ID       string
+	Deadline time.Time
+	Status   Status
+	// Stopped is true if the deployment was stopped.
+	Stopped bool
+	Err     error
+}
+
+func deadlineFile(id string) string {
+	return filepath.Join(viper.GetString(GlobalConfigDirKey), deadlinesDir, id+".json")
+}
+
+// recordDeadline records the deadline of deployment id.
+func recordDeadline(id string, deadline time.Time) error {
+	file := deadlineFile(id)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(deploymentDeadline{ID: id, Deadline: deadline})
+	if err != nil {
+		return err
+	}
+	// written atomically, for a reaper running at the same time.
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// lockReap takes the reap lock, returning a function releasing it.
+func lockReap() (func(), error) {
+	file := filepath.Join(filepath.Dir(deadlineFile("")), reapLockFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		info, statErr := os.Stat(file)
+		if statErr != nil || time.Since(info.ModTime()) < staleReapLock {
+			return nil, errors.New("another reap is running")
+		}
+		os.Remove(file)
+		f, err = os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return func() { os.Remove(file) }, nil
+}
+
+// loadDeadlines returns the recorded deadlines, earliest first.
+func loadDeadlines() ([]deploymentDeadline, error) {
+	files, err := filepath.Glob(deadlineFile("*"))
+	if err != nil {
+		return nil, err
+	}
+	var deadlines []deploymentDeadline
+	for _, file := range files {
+		var deadline deploymentDeadline
+		b, err := ioutil.ReadFile(file)
+		if err != nil || json.Unmarshal(b, &deadline) != nil || deadline.ID == "" {
+			continue
+		}
+		deadlines = append(deadlines, deadline)
+	}
+	sort.Slice(deadlines, func(i, j int) bool { return deadlines[i].Deadline.Before(deadlines[j].Deadline) })
+	return deadlines, nil
+}
+
+// Reap stops the started deployments past their recorded deadline.
+// Deadlines of finished deployments are removed.
+func (p deploymentJob) Reap(dryRun bool) ([]ReapResult, error) {
+	if !dryRun {
+		unlock, err := lockReap()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+	deadlines, err := loadDeadlines()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var results []ReapResult
+	for _, deadline := range deadlines {
+		job, err := p.getJob(JobTypeDeployment, deadline.ID)
+		if err == ErrNotFound || (err == nil && job.IsCompleted()) {
+			if !dryRun {
+				os.Remove(deadlineFile(deadline.ID))
+			}
+			continue
+		}
+		if deadline.Deadline.After(now) {
+			continue
+		}
+		result := ReapResult{ID: deadline.ID, Deadline: deadline.Deadline, Status: job.Status, Err: err}
+		switch {
+		case err != nil:
+		case !job.Status.Is(StatusStarted):
+			// queued deployments are reaped once started.
+			continue
+		case !dryRun:
+			logger.Info.Printf("stopping deployment %s, past its deadline %s", deadline.ID, deadline.Deadline.Format(time.RFC3339))
+			result.Err = p.Stop(deadline.ID)
+			if result.Err != nil {
+				// stopping already, e.g. by the platform's own ttl.
+				if job, err := p.getJob(JobTypeDeployment, deadline.ID); err == nil && (job.Status.Is(StatusTerminating) || job.IsCompleted()) {
+					result.Err = nil
+				}
+			}
+			result.Stopped = result.Err == nil
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ReapTable returns a table of reaped deployments.
+func ReapTable(results []ReapResult) printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: "id", Title: "deployment id"},
+			{Name: "status", Title: "status"},
+			{Name: "deadline", Title: "deadline"},
+			{Name: "result", Title: "result"},
+		},
+	}
+	for _, r := range results {
+		result := printer.Colored{Value: "past deadline", Color: printer.Yellow}
+		switch {
+		case r.Err != nil:
+			result = printer.Colored{Value: r.Err.Error(), Color: printer.Red}
+		case r.Stopped:
+			result = printer.Colored{Value: "stopped", Color: printer.Green}
+		}
+		table.Body = append(table.Body, printer.Row{
+			r.ID,
+			printer.Colored{Value: r.Status.lower(), Color: statusColor(r.Status)},
+			r.Deadline,
+			result,
+		})
+	}
+	return table
+}
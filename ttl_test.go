@@ -0,0 +1,94 @@
+package reco
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestReap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	viper.Set(GlobalConfigDirKey, dir)
+	defer viper.Set(GlobalConfigDirKey, nil)
+
+	statuses := map[string]Status{
+		"expired":  StatusStarted,
+		"queued":   StatusQueued,
+		"finished": StatusCompleted,
+		"alive":    StatusStarted,
+	}
+	var stopped []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		status, ok := statuses[parts[1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == "POST" {
+			stopped = append(stopped, parts[1])
+			return
+		}
+		json.NewEncoder(w).Encode(M{"value": M{
+			"id":     parts[1],
+			"events": []M{{"status": status, "timestamp": time.Now()}},
+		}})
+	}))
+	defer server.Close()
+
+	past := time.Now().Add(-time.Minute)
+	for _, id := range []string{"expired", "queued", "finished", "missing"} {
+		if err := recordDeadline(id, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recordDeadline("alive", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	job := deploymentJob{&clientImpl{platformServer: server.URL, Username: "user", Token: "token"}}
+
+	results, err := job.Reap(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "expired" || results[0].Stopped || len(stopped) != 0 {
+		t.Errorf("unexpected dry run results %+v, stopped %v", results, stopped)
+	}
+
+	// an overlapping reap holds the lock.
+	unlock, err := lockReap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := job.Reap(false); err == nil || len(stopped) != 0 {
+		t.Errorf("reaped while another reap was running, stopped %v", stopped)
+	}
+	unlock()
+
+	results, err = job.Reap(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Stopped || len(stopped) != 1 || stopped[0] != "expired" {
+		t.Errorf("unexpected results %+v, stopped %v", results, stopped)
+	}
+	deadlines, err := loadDeadlines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// finished and missing deployments are forgotten.
+	if len(deadlines) != 3 {
+		t.Errorf("expected 3 deadlines, got %+v", deadlines)
+	}
+}
@@ -0,0 +1,43 @@
+package reco
+
+import (
+	"io"
+
+	"github.com/ReconfigureIO/reco/printer"
+)
+
+var _ BuildJob = unsupportedJob{}
+var _ SimulationJob = unsupportedJob{}
+var _ DeploymentJob = unsupportedJob{}
+var _ Graph = unsupportedGraph{}
+var _ ProjectConfig = unsupportedProject{}
+
+// unsupportedJob is a Job for a job type a provider does not support.
+type unsupportedJob struct{}
+
+func (unsupportedJob) Start(Args) (string, error)                        { return "", errUnsupported }
+func (unsupportedJob) StartBuild(BuildOptions) (string, error)           { return "", errUnsupported }
+func (unsupportedJob) StartSimulation(SimulationOptions) (string, error) { return "", errUnsupported }
+func (unsupportedJob) StartDeployment(DeploymentOptions) (string, error) { return "", errUnsupported }
+func (unsupportedJob) Stop(id string) error                              { return errUnsupported }
+func (unsupportedJob) Status(id string) Status                           { return "" }
+func (unsupportedJob) List(filter M) (printer.Table, error)              { return printer.Table{}, errUnsupported }
+func (unsupportedJob) Log(id string, writer io.Writer) error             { return errUnsupported }
+
+// unsupportedGraph is a Graph for providers without graphs.
+type unsupportedGraph struct{}
+
+func (unsupportedGraph) Generate(Args) (string, error)        { return "", errUnsupported }
+func (unsupportedGraph) List(filter M) (printer.Table, error) { return printer.Table{}, errUnsupported }
+func (unsupportedGraph) Open(id string) (string, error)       { return "", errUnsupported }
+
+// unsupportedProject is a ProjectConfig for providers without projects.
+type unsupportedProject struct{}
+
+func (unsupportedProject) List(filter M) (printer.Table, error) {
+	return printer.Table{}, errUnsupported
+}
+func (unsupportedProject) list() ([]ProjectInfo, error) { return nil, errUnsupported }
+func (unsupportedProject) Create(name string) error     { return errUnsupported }
+func (unsupportedProject) Set(name string) error        { return errUnsupported }
+func (unsupportedProject) Get() (string, error)         { return "", errUnsupported }
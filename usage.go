@@ -0,0 +1,158 @@
+package reco
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+// RatesKey is the config key of the per-hour rates of job types
+// e.g. rates.deployment.
+const RatesKey = "rates"
+
+// UsageGroups are the groupings of usage.
+var UsageGroups = []string{"project", "type", "day"}
+
+// UsageReporter reports instance usage of jobs.
+type UsageReporter interface {
+	// Usage aggregates the instance-hours of the builds, simulations
+	// and deployments of all projects started since since, grouped
+	// by project, type or day.
+	Usage(since time.Time, by string) (Usage, error)
+}
+
+var _ UsageReporter = &clientImpl{}
+
+// Usage is the instance usage of jobs.
+type Usage struct {
+	By     string       `json:"by" yaml:"by"`
+	Since  time.Time    `json:"since" yaml:"since"`
+	Groups []UsageGroup `json:"groups" yaml:"groups"`
+	Total  UsageGroup   `json:"total" yaml:"total"`
+}
+
+// UsageGroup is the usage of a group of jobs.
+type UsageGroup struct {
+	Name  string  `json:"name" yaml:"name"`
+	Jobs  int     `json:"jobs" yaml:"jobs"`
+	Hours float64 `json:"hours" yaml:"hours"`
+	Cost  float64 `json:"cost" yaml:"cost"`
+}
+
+func (g *UsageGroup) add(hours, rate float64) {
+	g.Jobs++
+	g.Hours += hours
+	g.Cost += hours * rate
+}
+
+// Rate returns the configured per-hour rate of jobType, or 0.
+func Rate(jobType string) float64 {
+	return viper.GetFloat64(RatesKey + "." + jobType)
+}
+
+// usageJob is a job of a type.
+type usageJob struct {
+	jobType string
+	jobInfo
+}
+
+// jobHours returns the instance-hours of job. Jobs still running
+// are counted until now.
+func jobHours(job jobInfo, now time.Time) float64 {
+	if job.Duration > 0 {
+		return job.Duration.Hours()
+	}
+	if job.Status.Is(StatusStarted) && !job.Time.IsZero() {
+		return now.Sub(job.Time).Hours()
+	}
+	return 0
+}
+
+// aggregateUsage groups the usage of jobs started since since.
+func aggregateUsage(jobs []usageJob, since time.Time, by string, now time.Time) (Usage, error) {
+	var key func(job usageJob) string
+	switch by {
+	case "project":
+		key = func(job usageJob) string { return job.Project }
+	case "type":
+		key = func(job usageJob) string { return job.jobType }
+	case "day":
+		// days of the zone of since, as the user sees them.
+		key = func(job usageJob) string { return job.Time.In(since.Location()).Format("2006-01-02") }
+	default:
+		return Usage{}, fmt.Errorf("cannot group usage by '%s'. Usage can be grouped by %s", by, strings.Join(UsageGroups, ", "))
+	}
+
+	usage := Usage{By: by, Since: since, Total: UsageGroup{Name: "total"}}
+	groups := make(map[string]*UsageGroup)
+	for _, job := range jobs {
+		if job.Time.Before(since) {
+			continue
+		}
+		hours, rate := jobHours(job.jobInfo, now), Rate(job.jobType)
+		name := key(job)
+		group, ok := groups[name]
+		if !ok {
+			group = &UsageGroup{Name: name}
+			groups[name] = group
+		}
+		group.add(hours, rate)
+		usage.Total.add(hours, rate)
+	}
+	for _, group := range groups {
+		usage.Groups = append(usage.Groups, *group)
+	}
+	sort.Slice(usage.Groups, func(i, j int) bool { return usage.Groups[i].Name < usage.Groups[j].Name })
+	return usage, nil
+}
+
+// Usage lists the jobs of all projects to aggregate their usage.
+func (p *clientImpl) Usage(since time.Time, by string) (Usage, error) {
+	var jobs []usageJob
+	for _, jobType := range []string{JobTypeBuild, JobTypeSimulation, JobTypeDeployment} {
+		infos, err := p.listJobs(jobType, M{"all": true})
+		if err != nil {
+			return Usage{}, err
+		}
+		for _, info := range infos {
+			jobs = append(jobs, usageJob{jobType, info})
+		}
+	}
+	return aggregateUsage(jobs, since, by, time.Now())
+}
+
+// Table returns the usage groups as a table, with the total as the
+// last row. The hidden total column marks the total row, for formats
+// without a distinct total.
+func (u Usage) Table() printer.Table {
+	table := printer.Table{
+		Header: []printer.Column{
+			{Name: u.By, Title: u.By},
+			{Name: "jobs", Title: "jobs"},
+			{Name: "hours", Title: "instance hours"},
+			{Name: "cost", Title: "cost"},
+			{Name: "total", Title: "total", Hidden: true},
+		},
+	}
+	groups := make([]UsageGroup, len(u.Groups), len(u.Groups)+1)
+	copy(groups, u.Groups)
+	for i, g := range append(groups, u.Total) {
+		table.Body = append(table.Body, printer.Row{
+			g.Name,
+			g.Jobs,
+			round2(g.Hours),
+			round2(g.Cost),
+			printer.Marker(i == len(u.Groups)),
+		})
+	}
+	return table
+}
+
+// round2 rounds a non-negative value to 2 decimal places.
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
@@ -0,0 +1,91 @@
+package reco
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ReconfigureIO/reco/printer"
+	"github.com/spf13/viper"
+)
+
+func TestAggregateUsage(t *testing.T) {
+	viper.Set(RatesKey+".deployment", 2.0)
+	viper.Set(RatesKey+".build", 0.5)
+	defer viper.Set(RatesKey+".deployment", nil)
+	defer viper.Set(RatesKey+".build", nil)
+
+	now := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []usageJob{
+		{JobTypeBuild, jobInfo{Project: "a", Time: now.Add(-48 * time.Hour), Duration: 2 * time.Hour, Status: "completed"}},
+		{JobTypeDeployment, jobInfo{Project: "a", Time: now.Add(-3 * time.Hour), Status: "started"}},
+		{JobTypeSimulation, jobInfo{Project: "b", Time: now.Add(-time.Hour), Duration: 30 * time.Minute, Status: "completed"}},
+		{JobTypeDeployment, jobInfo{Project: "b", Time: now.Add(-time.Hour), Status: "queued"}},
+		// before since.
+		{JobTypeDeployment, jobInfo{Project: "a", Time: since.Add(-time.Hour), Duration: 10 * time.Hour}},
+	}
+
+	usage, err := aggregateUsage(jobs, since, "project", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []UsageGroup{
+		{Name: "a", Jobs: 2, Hours: 5, Cost: 7},
+		{Name: "b", Jobs: 2, Hours: 0.5, Cost: 0},
+	}
+	if len(usage.Groups) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, usage.Groups)
+	}
+	for i := range expected {
+		if usage.Groups[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], usage.Groups[i])
+		}
+	}
+	if usage.Total.Jobs != 4 || usage.Total.Hours != 5.5 || usage.Total.Cost != 7 {
+		t.Errorf("unexpected total %v", usage.Total)
+	}
+
+	usage, err = aggregateUsage(jobs, since, "day", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage.Groups) != 2 || usage.Groups[0].Name != "2026-09-13" || usage.Groups[1].Jobs != 3 {
+		t.Errorf("unexpected usage by day %v", usage.Groups)
+	}
+
+	usage, err = aggregateUsage(jobs, since, "type", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage.Groups) != 3 || usage.Groups[1].Name != JobTypeDeployment || usage.Groups[1].Cost != 6 {
+		t.Errorf("unexpected usage by type %v", usage.Groups)
+	}
+
+	// days are those of the zone of since.
+	zone := time.FixedZone("AEST", 10*60*60)
+	usage, err = aggregateUsage([]usageJob{{JobTypeBuild, jobInfo{Time: time.Date(2026, 9, 14, 20, 0, 0, 0, time.UTC)}}}, since.In(zone), "day", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage.Groups) != 1 || usage.Groups[0].Name != "2026-09-15" {
+		t.Errorf("expected day 2026-09-15 in %s, found %v", zone, usage.Groups)
+	}
+
+	table := usage.Table()
+	if last := len(table.Body) - 1; last != 1 || table.Value(last, "day") != "total" || table.Value(last, "jobs") != 1 || table.Value(last, "total") != printer.Marker(true) {
+		t.Errorf("expected a total row, found %v", table.Body)
+	}
+	if table.Value(0, "total") != printer.Marker(false) {
+		t.Errorf("expected a group row, found %v", table.Body[0])
+	}
+	usage.Groups = make([]UsageGroup, 1, 2)
+	usage.Groups[0].Name = "2026-09-15"
+	usage.Table()
+	if extra := usage.Groups[:2]; extra[1].Name != "" {
+		t.Errorf("Table wrote the total into the groups: %v", extra)
+	}
+
+	if _, err := aggregateUsage(jobs, since, "week", now); err == nil {
+		t.Error("expected error for unknown grouping")
+	}
+}
@@ -0,0 +1,178 @@
+package reco
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// goPackage is the subset of 'go list -json' output needed for vendoring.
+type goPackage struct {
+	ImportPath string
+	Dir        string
+	Standard   bool
+	Error      *struct {
+		Err string
+	}
+}
+
+// vendorDeps resolves the Go dependencies of the project in dir and copies
+// them into a temporary vendor directory ready to be archived alongside the
+// source. Module projects are resolved from the local module cache, other
+// projects from GOPATH. It returns an empty path if there is nothing to vendor.
+func vendorDeps(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := tmpDir()
+	if err != nil {
+		return "", err
+	}
+	vendorDir, err := filepath.Abs(filepath.Join(tmp, "vendor"))
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		err = vendorModules(dir, vendorDir)
+	} else {
+		err = vendorGopath(dir, vendorDir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(vendorDir); err != nil {
+		return "", nil
+	}
+	return vendorDir, nil
+}
+
+// vendorModules vendors a module project with 'go mod vendor'. Network
+// access is disabled so that only modules in the local cache are used.
+func vendorModules(dir, vendorDir string) error {
+	cmd := exec.Command("go", "mod", "vendor", "-o", vendorDir)
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	out, err := goCommand(cmd, dir)
+	if err != nil {
+		return fmt.Errorf("could not vendor dependencies from the local module cache\n\n%s", out)
+	}
+	return nil
+}
+
+// vendorGopath vendors a GOPATH project by copying every non standard
+// library package it imports into vendorDir.
+func vendorGopath(dir, vendorDir string) error {
+	cmd := exec.Command("go", "list", "-e", "-deps", "-json", "./...")
+	cmd.Env = append(os.Environ(), "GO111MODULE=off")
+	out, err := goCommand(cmd, dir)
+	if err != nil {
+		return fmt.Errorf("could not list dependencies\n\n%s", out)
+	}
+
+	var missing []string
+	decoder := json.NewDecoder(strings.NewReader(out))
+	for {
+		var pkg goPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		if pkg.Standard {
+			continue
+		}
+		if pkg.Error != nil {
+			missing = append(missing, pkg.ImportPath+": "+pkg.Error.Err)
+			continue
+		}
+		// skip the project's own packages.
+		if inDir(dir, pkg.Dir) && !inDir(filepath.Join(dir, "vendor"), pkg.Dir) {
+			continue
+		}
+		if err := copyPackage(pkg.Dir, filepath.Join(vendorDir, filepath.FromSlash(vendoredPath(pkg.ImportPath)))); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing dependencies:\n  %s", strings.Join(missing, "\n  "))
+	}
+	return nil
+}
+
+// goCommand runs cmd in dir and returns its combined output.
+func goCommand(cmd *exec.Cmd, dir string) (string, error) {
+	var out bytes.Buffer
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// vendoredPath strips any vendor directory prefix from an import path.
+func vendoredPath(importPath string) string {
+	if i := strings.LastIndex(importPath, "/vendor/"); i >= 0 {
+		return importPath[i+len("/vendor/"):]
+	}
+	return strings.TrimPrefix(importPath, "vendor/")
+}
+
+// inDir checks if path is dir or within dir.
+func inDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// copyPackage copies the regular files of a package directory,
+// excluding tests, into dest.
+func copyPackage(src, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	dir, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	stats, err := dir.Readdir(-1)
+	if err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		if !stat.Mode().IsRegular() || strings.HasSuffix(stat.Name(), "_test.go") {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, stat.Name()), filepath.Join(dest, stat.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
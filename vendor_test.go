@@ -0,0 +1,97 @@
+package reco
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVendoredPath(t *testing.T) {
+	paths := map[string]string{
+		"github.com/foo/bar":                           "github.com/foo/bar",
+		"vendor/github.com/foo/bar":                    "github.com/foo/bar",
+		"github.com/foo/app/vendor/github.com/foo/bar": "github.com/foo/bar",
+	}
+	for importPath, expected := range paths {
+		if p := vendoredPath(importPath); p != expected {
+			t.Errorf("vendoredPath(%q) = %q, expected %q", importPath, p, expected)
+		}
+	}
+}
+
+func TestInDir(t *testing.T) {
+	dir := filepath.Join("src", "project")
+	if !inDir(dir, dir) {
+		t.Error("inDir returned false for the directory itself")
+	}
+	if !inDir(dir, filepath.Join(dir, "cmd", "foo")) {
+		t.Error("inDir returned false for a subdirectory")
+	}
+	if inDir(dir, filepath.Join("src", "project2")) {
+		t.Error("inDir returned true for a sibling directory")
+	}
+	if inDir(dir, "src") {
+		t.Error("inDir returned true for a parent directory")
+	}
+}
+
+func TestVendorGopath(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	gopath, err := ioutil.TempDir("", "reco")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+	defer os.Setenv("GOPATH", os.Getenv("GOPATH"))
+	os.Setenv("GOPATH", gopath)
+
+	files := map[string]string{
+		"src/example.com/present/present.go":   "package present\n\nconst Value = 1\n",
+		"src/example.com/present/x_test.go":    "package present\n",
+		"src/example.com/project/main.go":      "package main\n\nimport _ \"example.com/present\"\n\nfunc main() {}\n",
+		"src/example.com/project/cmd/a/a.go":   "package main\n\nimport _ \"example.com/missing\"\n\nfunc main() {}\n",
+		"src/example.com/project/util/util.go": "package util\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(gopath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	project := filepath.Join(gopath, "src", "example.com", "project")
+
+	vendorDir := filepath.Join(gopath, "vendor")
+	err = vendorGopath(project, vendorDir)
+	if err == nil || !strings.Contains(err.Error(), "example.com/missing") {
+		t.Errorf("expected example.com/missing to be reported missing, got %v", err)
+	} else if strings.Contains(err.Error(), "example.com/present") {
+		t.Errorf("example.com/present reported missing: %v", err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(project, "cmd")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(vendorDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := vendorGopath(project, vendorDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(vendorDir, "example.com", "present", "present.go")); err != nil {
+		t.Errorf("present dependency was not vendored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(vendorDir, "example.com", "present", "x_test.go")); err == nil {
+		t.Error("tests of dependencies were vendored")
+	}
+	if _, err := os.Stat(filepath.Join(vendorDir, "example.com", "project")); err == nil {
+		t.Error("the project's own packages were vendored")
+	}
+}